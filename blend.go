@@ -0,0 +1,199 @@
+package color
+
+import "math"
+
+// BlendMode describes one of the separable blend modes defined by the
+// [Compositing and Blending] specification, as used by CSS' mix-blend-mode.
+//
+// [Compositing and Blending]: https://www.w3.org/TR/compositing-1/#blending
+type BlendMode int
+
+const (
+	Multiply BlendMode = iota
+	Screen
+	Overlay
+	Darken
+	Lighten
+	ColorDodge
+	ColorBurn
+	HardLight
+	SoftLight
+	Difference
+	Exclusion
+)
+
+// blendFuncs maps blend modes to functions that blend a single channel of the
+// backdrop (cb) and the source (cs).
+var blendFuncs = [...]func(cb, cs float64) float64{
+	Multiply: blendMultiply,
+	Screen:   blendScreen,
+	Overlay: func(cb, cs float64) float64 {
+		return blendHardLight(cs, cb)
+	},
+	Darken: func(cb, cs float64) float64 {
+		return min(cb, cs)
+	},
+	Lighten: func(cb, cs float64) float64 {
+		return max(cb, cs)
+	},
+	ColorDodge: func(cb, cs float64) float64 {
+		switch {
+		case cb == 0:
+			return 0
+		case cs == 1:
+			return 1
+		default:
+			return min(1, cb/(1-cs))
+		}
+	},
+	ColorBurn: func(cb, cs float64) float64 {
+		switch {
+		case cb == 1:
+			return 1
+		case cs == 0:
+			return 0
+		default:
+			return 1 - min(1, (1-cb)/cs)
+		}
+	},
+	HardLight: blendHardLight,
+	SoftLight: func(cb, cs float64) float64 {
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		var d float64
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		} else {
+			d = math.Sqrt(cb)
+		}
+		return cb + (2*cs-1)*(d-cb)
+	},
+	Difference: func(cb, cs float64) float64 {
+		return math.Abs(cb - cs)
+	},
+	Exclusion: func(cb, cs float64) float64 {
+		return cb + cs - 2*cb*cs
+	},
+}
+
+func blendMultiply(cb, cs float64) float64 {
+	return cb * cs
+}
+
+func blendScreen(cb, cs float64) float64 {
+	return cb + cs - cb*cs
+}
+
+func blendHardLight(cb, cs float64) float64 {
+	if cs <= 0.5 {
+		return blendMultiply(cb, 2*cs)
+	}
+	return blendScreen(cb, 2*cs-1)
+}
+
+// Blend blends the source color onto the backdrop color using the blend mode
+// and returns the result in sRGB. Blending operates on the sRGB channels of
+// the two colors. The alpha values are taken into account as described by the
+// specification, with the blended color composited onto the backdrop using
+// source-over compositing.
+func Blend(backdrop, source *Color, mode BlendMode) Color {
+	f := blendFuncs[mode]
+	b := backdrop.Convert(SRGB)
+	s := source.Convert(SRGB)
+	αb, αs := b.Alpha, s.Alpha
+	αo := αs + αb*(1-αs)
+	if αo == 0 {
+		return Make(SRGB, 0, 0, 0, 0)
+	}
+	var values [3]float64
+	for i := range values {
+		cb, cs := b.Values[i], s.Values[i]
+		co := αs*(1-αb)*cs + αs*αb*f(cb, cs) + (1-αs)*αb*cb
+		values[i] = co / αo
+	}
+	return Make(SRGB, values[0], values[1], values[2], αo)
+}
+
+// PorterDuffOp describes one of the Porter-Duff compositing operators, as
+// defined by the [Compositing and Blending] specification.
+//
+// [Compositing and Blending]: https://www.w3.org/TR/compositing-1/#porterduffcompositingoperators
+type PorterDuffOp int
+
+const (
+	CompositeClear PorterDuffOp = iota
+	CompositeCopy
+	CompositeDestination
+	CompositeSourceOver
+	CompositeDestinationOver
+	CompositeSourceIn
+	CompositeDestinationIn
+	CompositeSourceOut
+	CompositeDestinationOut
+	CompositeSourceAtop
+	CompositeDestinationAtop
+	CompositeXor
+	CompositeLighter
+)
+
+// Over composites source over backdrop. It is equivalent to calling
+// [Composite] with [CompositeSourceOver].
+func Over(source, backdrop *Color) Color {
+	return Composite(source, backdrop, CompositeSourceOver)
+}
+
+// Composite composites source and backdrop using the Porter-Duff operator op
+// and returns the result in sRGB. Colors are composited using straight (that
+// is, not premultiplied) sRGB values. If the resulting alpha is zero, Composite
+// returns transparent black.
+func Composite(source, backdrop *Color, op PorterDuffOp) Color {
+	s := source.Convert(SRGB)
+	b := backdrop.Convert(SRGB)
+	αs, αb := s.Alpha, b.Alpha
+
+	// The fractions of the source and backdrop that contribute to the result.
+	var fa, fb float64
+	switch op {
+	case CompositeClear:
+		fa, fb = 0, 0
+	case CompositeCopy:
+		fa, fb = 1, 0
+	case CompositeDestination:
+		fa, fb = 0, 1
+	case CompositeSourceOver:
+		fa, fb = 1, 1-αs
+	case CompositeDestinationOver:
+		fa, fb = 1-αb, 1
+	case CompositeSourceIn:
+		fa, fb = αb, 0
+	case CompositeDestinationIn:
+		fa, fb = 0, αs
+	case CompositeSourceOut:
+		fa, fb = 1-αb, 0
+	case CompositeDestinationOut:
+		fa, fb = 0, 1-αs
+	case CompositeSourceAtop:
+		fa, fb = αb, 1-αs
+	case CompositeDestinationAtop:
+		fa, fb = 1-αb, αs
+	case CompositeXor:
+		fa, fb = 1-αb, 1-αs
+	case CompositeLighter:
+		fa, fb = 1, 1
+	default:
+		panic("invalid Porter-Duff operator")
+	}
+
+	αo := αs*fa + αb*fb
+	if αo == 0 {
+		return Make(SRGB, 0, 0, 0, 0)
+	}
+	var values [3]float64
+	for i := range values {
+		co := αs*fa*s.Values[i] + αb*fb*b.Values[i]
+		values[i] = co / αo
+	}
+	// Make clamps the alpha, which is necessary for CompositeLighter.
+	return Make(SRGB, values[0], values[1], values[2], αo)
+}
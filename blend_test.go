@@ -0,0 +1,111 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBlend(t *testing.T) {
+	black := Make(SRGB, 0, 0, 0, 1)
+	white := Make(SRGB, 1, 1, 1, 1)
+	colors := []Color{
+		Make(SRGB, 0.2, 0.5, 0.9, 1),
+		Make(SRGB, 1, 0, 0, 1),
+		Make(DisplayP3, 0.3, 0.6, 0.1, 1),
+	}
+
+	for _, c := range colors {
+		if got := Blend(&c, &black, Multiply); got != black {
+			t.Errorf("multiply %v: got %v, want %v", c, got, black)
+		}
+		if got := Blend(&black, &c, Multiply); got != black {
+			t.Errorf("multiply %v: got %v, want %v", c, got, black)
+		}
+		if got := Blend(&c, &white, Screen); math.Abs(got.Values[0]-1) > 1e-12 ||
+			math.Abs(got.Values[1]-1) > 1e-12 || math.Abs(got.Values[2]-1) > 1e-12 {
+			t.Errorf("screen %v: got %v, want %v", c, got, white)
+		}
+	}
+
+	for _, c1 := range colors {
+		for _, c2 := range colors {
+			d1 := Blend(&c1, &c2, Difference)
+			d2 := Blend(&c2, &c1, Difference)
+			if d1 != d2 {
+				t.Errorf("difference of %v and %v isn't symmetric: %v != %v", c1, c2, d1, d2)
+			}
+		}
+	}
+
+	// Every mode must be defined and stay within [0, 1] for in-gamut inputs.
+	for mode := range BlendMode(len(blendFuncs)) {
+		for _, c1 := range colors[:2] {
+			for _, c2 := range colors[:2] {
+				got := Blend(&c1, &c2, mode)
+				for _, v := range got.Values {
+					if v < 0 || v > 1 || math.IsNaN(v) {
+						t.Errorf("mode %d: got %v", mode, got)
+					}
+				}
+			}
+		}
+	}
+
+	// A transparent source leaves the backdrop unchanged.
+	transparent := Make(SRGB, 1, 1, 1, 0)
+	if got := Blend(&colors[0], &transparent, Difference); got != colors[0] {
+		t.Errorf("got %v, want %v", got, colors[0])
+	}
+}
+
+func TestOver(t *testing.T) {
+	src := Make(SRGB, 1, 0, 0, 1)
+	dst := Make(SRGB, 0, 0, 1, 1)
+	if got := Over(&src, &dst); got != src {
+		t.Errorf("got %v, want %v", got, src)
+	}
+
+	src.Alpha = 0.5
+	if got, want := Over(&src, &dst), Make(SRGB, 0.5, 0, 0.5, 1); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Half-transparent over half-transparent
+	dst.Alpha = 0.5
+	got := Over(&src, &dst)
+	want := Make(SRGB, 2.0/3, 0, 1.0/3, 0.75)
+	for i := range got.Values {
+		if math.Abs(got.Values[i]-want.Values[i]) > 1e-12 || got.Alpha != want.Alpha {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+
+	transparent := Make(SRGB, 1, 1, 1, 0)
+	if got, want := Over(&transparent, &transparent), Make(SRGB, 0, 0, 0, 0); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestComposite(t *testing.T) {
+	src := Make(SRGB, 1, 0, 0, 0.5)
+	dst := Make(SRGB, 0, 0, 1, 1)
+	tests := []struct {
+		op   PorterDuffOp
+		want Color
+	}{
+		{CompositeClear, Make(SRGB, 0, 0, 0, 0)},
+		{CompositeCopy, src},
+		{CompositeDestination, dst},
+		{CompositeSourceIn, src},
+		{CompositeDestinationIn, Make(SRGB, 0, 0, 1, 0.5)},
+		{CompositeSourceOut, Make(SRGB, 0, 0, 0, 0)},
+		{CompositeSourceAtop, Make(SRGB, 0.5, 0, 0.5, 1)},
+		{CompositeXor, Make(SRGB, 0, 0, 1, 0.5)},
+	}
+	for _, tt := range tests {
+		if got := Composite(&src, &dst, tt.op); got != tt.want {
+			t.Errorf("op %d: got %v, want %v", tt.op, got, tt.want)
+		}
+	}
+}
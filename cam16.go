@@ -1,8 +1,15 @@
 package color
 
-// The viewing environment. For advice on choosing values, see "Usage Guidelines
-// for CIECAM97s" (2000) by Moroney.
-type _environment struct {
+import "math"
+
+func init() {
+	RegisterSpace(CAM16UCS)
+}
+
+// CAM16Environment describes the viewing environment of the CAM16 color
+// appearance model. For advice on choosing values, see "Usage Guidelines for
+// CIECAM97s" (2000) by Moroney.
+type CAM16Environment struct {
 	White *Chromaticity
 	// The average luminance of the environment in cd/m² (a.k.a. nits). Under a
 	// "gray world" assumption this is 20% of the luminance of a white
@@ -22,3 +29,225 @@ type _environment struct {
 	// the other fields.
 	Discounting bool
 }
+
+// cam16Surrounds are the F, c, and Nc parameters for dark, dim, and average
+// surrounds.
+var cam16Surrounds = [3][3]float64{
+	{0.8, 0.525, 0.8},
+	{0.9, 0.59, 0.9},
+	{1, 0.69, 1},
+}
+
+// CAM16SRGBEnvironment is the typical viewing environment of sRGB: a D65 white
+// point, an ambient illuminance of 64 lux, a gray world background, and an
+// average surround.
+var CAM16SRGBEnvironment = &CAM16Environment{
+	White:               WhitesSRGBD65,
+	AdaptingLuminance:   64 / math.Pi * 0.2,
+	BackgroundLuminance: 0.2,
+	Surround:            2,
+}
+
+// viewingConditions are the parameters of CAM16 derived from an environment.
+type viewingConditions struct {
+	fl, flRoot float64
+	n, z       float64
+	nbb, ncb   float64
+	c, nc      float64
+	aW         float64
+	dRGB       [3]float64
+	dRGBInv    [3]float64
+}
+
+func (env *CAM16Environment) viewingConditions() *viewingConditions {
+	var vc viewingConditions
+
+	xyzW := env.White.XYZ()
+	for i := range xyzW {
+		xyzW[i] *= 100
+	}
+	la := env.AdaptingLuminance
+	yb := env.BackgroundLuminance * 100
+	yw := xyzW[1]
+	rgbW := MulVecMat(&xyzW, &CAT16.ToCone)
+
+	s := min(max(env.Surround, 0), 2)
+	lo := min(int(s), 1)
+	f := lerp(cam16Surrounds[lo][0], cam16Surrounds[lo+1][0], s-float64(lo))
+	vc.c = lerp(cam16Surrounds[lo][1], cam16Surrounds[lo+1][1], s-float64(lo))
+	vc.nc = lerp(cam16Surrounds[lo][2], cam16Surrounds[lo+1][2], s-float64(lo))
+
+	k := 1 / (5*la + 1)
+	k4 := k * k * k * k
+	vc.fl = k4*la + 0.1*(1-k4)*(1-k4)*math.Cbrt(5*la)
+	vc.flRoot = math.Pow(vc.fl, 0.25)
+
+	vc.n = yb / yw
+	vc.z = 1.48 + math.Sqrt(vc.n)
+	vc.nbb = 0.725 * math.Pow(vc.n, -0.2)
+	vc.ncb = vc.nbb
+
+	d := 1.0
+	if !env.Discounting {
+		d = min(max(f*(1-1/3.6*math.Exp((-la-42)/92)), 0), 1)
+	}
+	var rgbCW [3]float64
+	for i, c := range rgbW {
+		vc.dRGB[i] = lerp(1, yw/c, d)
+		vc.dRGBInv[i] = 1 / vc.dRGB[i]
+		rgbCW[i] = c * vc.dRGB[i]
+	}
+	rgbAW := cam16Adapt(rgbCW, vc.fl)
+	vc.aW = vc.nbb * (2*rgbAW[0] + rgbAW[1] + 0.05*rgbAW[2])
+	return &vc
+}
+
+func cam16Adapt(rgb [3]float64, fl float64) [3]float64 {
+	for i, c := range rgb {
+		x := math.Pow(fl*math.Abs(c)*0.01, 0.42)
+		rgb[i] = math.Copysign(400*x/(x+27.13), c)
+	}
+	return rgb
+}
+
+func cam16Unadapt(rgb [3]float64, fl float64) [3]float64 {
+	k := 100 / fl * math.Pow(27.13, 1/0.42)
+	for i, c := range rgb {
+		a := math.Abs(c)
+		rgb[i] = math.Copysign(k*math.Pow(a/(400-a), 1/0.42), c)
+	}
+	return rgb
+}
+
+// cam16 holds the CAM16 correlates of a color.
+type cam16 struct {
+	// Lightness
+	J float64
+	// Chroma
+	C float64
+	// Hue angle in degrees
+	h float64
+	// Colorfulness
+	M float64
+}
+
+// xyzToCAM16 computes the CAM16 correlates of the XYZ color xyz, whose white
+// has Y = 1.
+func xyzToCAM16(xyz [3]float64, vc *viewingConditions) cam16 {
+	for i := range xyz {
+		xyz[i] *= 100
+	}
+	rgb := MulVecMat(&xyz, &CAT16.ToCone)
+	for i := range rgb {
+		rgb[i] *= vc.dRGB[i]
+	}
+	rgbA := cam16Adapt(rgb, vc.fl)
+
+	a := rgbA[0] + (-12*rgbA[1]+rgbA[2])/11
+	b := (rgbA[0] + rgbA[1] - 2*rgbA[2]) / 9
+	hRad := math.Mod(math.Atan2(b, a)+2*math.Pi, 2*math.Pi)
+
+	et := 0.25 * (math.Cos(hRad+2) + 3.8)
+	var t float64
+	if den := rgbA[0] + rgbA[1] + 1.05*rgbA[2] + 0.305; den != 0 {
+		t = 5e4 / 13 * vc.nc * vc.ncb * et * math.Hypot(a, b) / den
+	}
+	alpha := math.Pow(t, 0.9) * math.Pow(1.64-math.Pow(0.29, vc.n), 0.73)
+
+	A := vc.nbb * (2*rgbA[0] + rgbA[1] + 0.05*rgbA[2])
+	jRoot := math.Pow(max(A/vc.aW, 0), 0.5*vc.c*vc.z)
+	C := alpha * jRoot
+	return cam16{
+		J: 100 * jRoot * jRoot,
+		C: C,
+		h: hRad * 180 / math.Pi,
+		M: C * vc.flRoot,
+	}
+}
+
+// cam16ToXYZ computes the XYZ color, whose white has Y = 1, described by the
+// CAM16 lightness J, chroma C, and hue angle h in degrees.
+func cam16ToXYZ(J, C, h float64, vc *viewingConditions) [3]float64 {
+	if J <= 0 {
+		return [3]float64{}
+	}
+	hRad := h * math.Pi / 180
+	jRoot := math.Sqrt(J) * 0.1
+	alpha := C / jRoot
+	t := math.Pow(alpha*math.Pow(1.64-math.Pow(0.29, vc.n), -0.73), 10.0/9.0)
+	et := 0.25 * (math.Cos(hRad+2) + 3.8)
+	A := vc.aW * math.Pow(jRoot, 2/vc.c/vc.z)
+
+	p1 := 5e4 / 13 * vc.nc * vc.ncb * et
+	p2 := A / vc.nbb
+	sin, cos := math.Sincos(hRad)
+	var r float64
+	if den := 23*p1 + t*(11*cos+108*sin); den != 0 {
+		r = 23 * (p2 + 0.305) * t / den
+	}
+	a := r * cos
+	b := r * sin
+
+	rgbA := [3]float64{
+		(460*p2 + 451*a + 288*b) / 1403,
+		(460*p2 - 891*a - 261*b) / 1403,
+		(460*p2 - 220*a - 6300*b) / 1403,
+	}
+	rgb := cam16Unadapt(rgbA, vc.fl)
+	for i := range rgb {
+		rgb[i] *= vc.dRGBInv[i]
+	}
+	xyz := MulVecMat(&rgb, &CAT16.FromCone)
+	for i := range xyz {
+		xyz[i] /= 100
+	}
+	return xyz
+}
+
+// The coefficients of CAM16-UCS, from Li et al., "Comprehensive color solutions:
+// CAM16, CAT16, and CAM16-UCS" (2017).
+const (
+	cam16UCSc1 = 0.007
+	cam16UCSc2 = 0.0228
+)
+
+// xyzToCAM16UCS computes the CAM16-UCS coordinates J', a', and b' of the XYZ
+// color xyz, whose white has Y = 1.
+func xyzToCAM16UCS(xyz [3]float64, vc *viewingConditions) [3]float64 {
+	cam := xyzToCAM16(xyz, vc)
+	j := (1 + 100*cam16UCSc1) * cam.J / (1 + cam16UCSc1*cam.J)
+	m := math.Log1p(cam16UCSc2*cam.M) / cam16UCSc2
+	sin, cos := math.Sincos(cam.h * math.Pi / 180)
+	return [3]float64{j, m * cos, m * sin}
+}
+
+// cam16UCSToXYZ is the inverse of xyzToCAM16UCS.
+func cam16UCSToXYZ(ucs [3]float64, vc *viewingConditions) [3]float64 {
+	j := ucs[0] / (1 + 100*cam16UCSc1 - cam16UCSc1*ucs[0])
+	m := math.Expm1(math.Hypot(ucs[1], ucs[2])*cam16UCSc2) / cam16UCSc2
+	h := math.Atan2(ucs[2], ucs[1]) * 180 / math.Pi
+	return cam16ToXYZ(j, m/vc.flRoot, h, vc)
+}
+
+var cam16SRGBViewingConditions = CAM16SRGBEnvironment.viewingConditions()
+
+// CAM16UCS is the CAM16-UCS uniform color space, whose coordinates J', a',
+// and b' are derived from the CAM16 color appearance model, in the viewing
+// environment [CAM16SRGBEnvironment]. Use [DeltaECAM16] to compute color
+// differences in other environments.
+var CAM16UCS = (&Space{
+	ID:   "cam16-ucs",
+	Name: "CAM16-UCS",
+	Coords: [3]Coordinate{
+		{Name: "Lightness", Range: infty, RefRange: [2]float64{0, 100}},
+		{Name: "a", Range: infty, RefRange: [2]float64{-50, 50}},
+		{Name: "b", Range: infty, RefRange: [2]float64{-50, 50}},
+	},
+	Base: XYZ_D65,
+	FromBase: func(c *[3]float64) [3]float64 {
+		return xyzToCAM16UCS(*c, cam16SRGBViewingConditions)
+	},
+	ToBase: func(c *[3]float64) [3]float64 {
+		return cam16UCSToXYZ(*c, cam16SRGBViewingConditions)
+	},
+}).Init()
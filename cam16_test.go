@@ -0,0 +1,91 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCAM16(t *testing.T) {
+	// Reference values from colour-science's XYZ_to_CAM16.
+	white := Chromaticity{95.05 / (95.05 + 100 + 108.88), 100 / (95.05 + 100 + 108.88)}
+	env := &CAM16Environment{
+		White:               &white,
+		AdaptingLuminance:   318.31,
+		BackgroundLuminance: 0.2,
+		Surround:            2,
+	}
+	cam := xyzToCAM16([3]float64{0.1901, 0.2, 0.2178}, env.viewingConditions())
+	want := cam16{J: 41.7312079, C: 0.1033557, h: 217.0679597, M: 0.1074367}
+	if math.Abs(cam.J-want.J) > 1e-4 || math.Abs(cam.C-want.C) > 1e-4 ||
+		math.Abs(cam.h-want.h) > 1e-2 || math.Abs(cam.M-want.M) > 1e-4 {
+		t.Errorf("got %+v, want %+v", cam, want)
+	}
+}
+
+func TestCAM16UCS(t *testing.T) {
+	for _, c := range []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0.2, 0.5, 0.8, 1),
+		Make(SRGB, 1, 1, 1, 1),
+		Make(DisplayP3, 0.1, 0.9, 0.3, 1),
+	} {
+		ucs := c.Convert(CAM16UCS)
+		back := ucs.Convert(c.Space)
+		if !approxValues(back.Values, c.Values, 1e-9) {
+			t.Errorf("%v: got %v after round trip through CAM16-UCS", c, back)
+		}
+	}
+
+	// White has a J' of 100. Because adaptation to the white point is
+	// incomplete, it isn't exactly neutral.
+	white := Make(SRGB, 1, 1, 1, 1)
+	ucs := white.Convert(CAM16UCS)
+	if math.Abs(ucs.Values[0]-100) > 1e-9 || math.Hypot(ucs.Values[1], ucs.Values[2]) > 3 {
+		t.Errorf("got %v for white", ucs)
+	}
+}
+
+func TestDeltaECAM16(t *testing.T) {
+	a := Make(SRGB, 0.8, 0.3, 0.2, 1)
+	b := Make(SRGB, 0.7, 0.35, 0.25, 1)
+
+	if d := DeltaECAM16(&a, &a, nil); d != 0 {
+		t.Errorf("got %g for identical colors, want 0", d)
+	}
+	d1 := DeltaECAM16(&a, &b, nil)
+	if d2 := DeltaECAM16(&b, &a, nil); math.Abs(d1-d2) > 1e-12 {
+		t.Errorf("not symmetric: %g != %g", d1, d2)
+	}
+	if d := DeltaDistance(&a, &b, CAM16UCS); math.Abs(d-d1) > 1e-9 {
+		t.Errorf("got %g, want %g, like DeltaDistance in CAM16UCS", d1, d)
+	}
+
+	// Two samples from colour-science's CAM16 test suite that share a viewing
+	// environment. The expected difference is the distance between the
+	// CAM16-UCS coordinates derived from colour-science's J, C, and h for
+	// these samples.
+	white := &Chromaticity{X: 109.85 / 245.43, Y: 100 / 245.43}
+	env := &CAM16Environment{
+		White:               white,
+		AdaptingLuminance:   318.31,
+		BackgroundLuminance: 0.2,
+		Surround:            2,
+	}
+	s1 := Make(XYZ_D65, 0.0353, 0.0656, 0.0214, 1)
+	s2 := Make(XYZ_D65, 0.1901, 0.2000, 0.2178, 1)
+	if d, want := DeltaECAM16(&s1, &s2, env), 50.71881533; math.Abs(d-want) > 1e-6 {
+		t.Errorf("got %.8f, want %.8f", d, want)
+	}
+
+	// The same pair of colors looks different under different viewing
+	// conditions.
+	dim := &CAM16Environment{
+		White:               WhitesSRGBD65,
+		AdaptingLuminance:   4,
+		BackgroundLuminance: 0.1,
+		Surround:            0,
+	}
+	if d := DeltaECAM16(&a, &b, dim); math.Abs(d-d1) < 0.1 {
+		t.Errorf("got %g in a dark environment, want a value different from %g", d, d1)
+	}
+}
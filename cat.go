@@ -1,9 +1,14 @@
 package color
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"sync"
+)
 
 var (
 	Bradford = &CAT{
+		Name: "Bradford",
 		ToCone: [3][3]float64{
 			{+0.8951, +0.2664, -0.1614},
 			{-0.7502, +1.7135, +0.0367},
@@ -17,6 +22,7 @@ var (
 	}
 
 	CAT16 = &CAT{
+		Name: "CAT16",
 		ToCone: [3][3]float64{
 			{0.401288, 0.650173, -0.051461},
 			{-0.250268, 1.204414, 0.045854},
@@ -28,12 +34,77 @@ var (
 			{-0.01584149884933386, -0.03412293802851557, 1.0499644368778496},
 		},
 	}
+
+	// VonKries uses the Hunt-Pointer-Estevez cone fundamentals, normalized to
+	// D65.
+	VonKries = &CAT{
+		Name: "von Kries",
+		ToCone: [3][3]float64{
+			{0.40024, 0.70760, -0.08081},
+			{-0.22630, 1.16532, 0.04570},
+			{0, 0, 0.91822},
+		},
+		FromCone: [3][3]float64{
+			{1.8599363874558397, -1.1293816185800916, 0.21989740959619328},
+			{0.3611914362417676, 0.6388124632850422, -6.370596838650885e-06},
+			{0, 0, 1.0890636230968613},
+		},
+	}
+
+	CMCCAT2000 = &CAT{
+		Name: "CMCCAT2000",
+		ToCone: [3][3]float64{
+			{0.7982, 0.3389, -0.1371},
+			{-0.5918, 1.5512, 0.0406},
+			{0.0008, 0.0239, 0.9753},
+		},
+		FromCone: [3][3]float64{
+			{1.0764500486786395, -0.23766238809256302, 0.16121233941392346},
+			{0.41096432547977585, 0.5543418041471031, 0.03469387037312099},
+			{-0.010953765423879377, -0.01338935630948602, 1.0243431217333654},
+		},
+	}
+
+	Sharp = &CAT{
+		Name: "Sharp",
+		ToCone: [3][3]float64{
+			{1.2694, -0.0988, -0.1706},
+			{-0.8364, 1.8006, 0.0357},
+			{0.0297, -0.0315, 1.0018},
+		},
+		FromCone: [3][3]float64{
+			{0.815633309578485, 0.04715477881785128, 0.1372166270815455},
+			{0.3791143991110205, 0.576942424774199, 0.04400087035725814},
+			{-0.01226013747502881, 0.016743051955976328, 0.9955187598242481},
+		},
+	}
+
+	// XYZScaling scales the XYZ values directly. It is the simplest, and least
+	// accurate, CAT.
+	XYZScaling = &CAT{
+		Name: "XYZ scaling",
+		ToCone: [3][3]float64{
+			{1, 0, 0},
+			{0, 1, 0},
+			{0, 0, 1},
+		},
+		FromCone: [3][3]float64{
+			{1, 0, 0},
+			{0, 1, 0},
+			{0, 0, 1},
+		},
+	}
 )
 
+// CATs returns all predefined chromatic adaptation transforms.
+func CATs() []*CAT {
+	return []*CAT{Bradford, CAT16, VonKries, CMCCAT2000, Sharp, XYZScaling}
+}
+
 var (
 	// Standard illuminants for the CIE 1931 standard observer, from tables T.3,
 	// T.8, T.8.2, and T.9 in CIE 15:2004.
-	WhitesCIE2004TwoDegA      = &Chromaticity{0.44758, 040745}
+	WhitesCIE2004TwoDegA      = &Chromaticity{0.44758, 0.40745}
 	WhitesCIE2004TwoDegC      = &Chromaticity{0.31006, 0.31616}
 	WhitesCIE2004TwoDegD50    = &Chromaticity{0.34567, 0.35851}
 	WhitesCIE2004TwoDegD55    = &Chromaticity{0.33243, 0.34744}
@@ -101,24 +172,35 @@ var (
 // illuminants.
 //
 // The color temperature, specified in Kelvin, must be between 4000 K and
-// 25,000 K.
+// 25,000 K. See [MakeCIEDaylightIlluminantErr] for a variant that returns an
+// error instead of panicking.
 func MakeCIEDaylightIlluminant(temp float64) Chromaticity {
+	chr, err := MakeCIEDaylightIlluminantErr(temp)
+	if err != nil {
+		panic(err)
+	}
+	return chr
+}
+
+// MakeCIEDaylightIlluminantErr is like [MakeCIEDaylightIlluminant], but returns
+// an error if the color temperature isn't between 4000 K and 25,000 K.
+func MakeCIEDaylightIlluminantErr(temp float64) (Chromaticity, error) {
 	switch {
 	case temp < 4000, temp > 25_000:
 		fallthrough
 	default:
 		// function not defined for this range
-		panic(fmt.Sprintf("color temperature %v is not in range [4000, 25000]", temp))
+		return Chromaticity{}, fmt.Errorf("color temperature %v is not in range [4000, 25000]", temp)
 	case temp <= 7000:
 		// Formula taken from CIE 15:2004, page 3, equations 3.2 and 3.3.
 		x := (-4.6070e9)/(temp*temp*temp) + 2.9678e6/(temp*temp) + 0.09911e3/temp + 0.244063
 		y := -3*x*x + 2.870*x - 0.275
-		return Chromaticity{x, y}
+		return Chromaticity{x, y}, nil
 	case temp <= 25_000:
 		// Formula taken from CIE 15:2004, pages 3-4, equations 3.2 and 3.4.
 		x := (-2.0064e9)/(temp*temp*temp) + 1.9018e6/(temp*temp) + 0.24748e3/temp + 0.237040
 		y := -3*x*x + 2.870*x - 0.275
-		return Chromaticity{x, y}
+		return Chromaticity{x, y}, nil
 	}
 }
 
@@ -130,21 +212,109 @@ func MakeCIEDaylightIlluminant(temp float64) Chromaticity {
 // using [CAT.Adapt] for one-offs, or by combining [CAT.Matrix] and [Adapt],
 // which allows reusing matrices computed for pairs of white points.
 type CAT struct {
+	Name     string
 	ToCone   [3][3]float64
 	FromCone [3][3]float64
 }
 
+// NewCAT returns a CAT with the given matrix for converting from XYZ to cone
+// responses. The inverse matrix is computed automatically. It panics if toCone
+// isn't invertible.
+func NewCAT(toCone [3][3]float64) *CAT {
+	fromCone, ok := InvertMat3(&toCone)
+	if !ok {
+		panic("cone response matrix is singular")
+	}
+	return &CAT{ToCone: toCone, FromCone: fromCone}
+}
+
+// Validate checks that cat's FromCone is the inverse of its ToCone, which
+// guards against transcription errors in hand-written matrices.
+func (cat *CAT) Validate() error {
+	const ϵ = 1e-6
+	m := MulMatMat(&cat.ToCone, &cat.FromCone)
+	for i := range m {
+		for j := range m[i] {
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			if math.Abs(m[i][j]-want) > ϵ {
+				return fmt.Errorf("FromCone isn't the inverse of ToCone: ToCone·FromCone = %v", m)
+			}
+		}
+	}
+	return nil
+}
+
 func (cat *CAT) Adapt(xyz *[3]float64, src, dst *Chromaticity) [3]float64 {
 	m := cat.Matrix(src, dst)
 	return Adapt(xyz, &m)
 }
 
+// AdaptColor converts c to XYZ relative to the white point of c's color space,
+// adapts it to the white point dst, and returns the adapted color in an XYZ
+// space whose white point is dst. That space is [XYZ_D65] or [XYZ_D50] if dst
+// is their white point, and a space created with [NewXYZSpace] otherwise,
+// which is shared by all adaptations to the same white point.
+func (cat *CAT) AdaptColor(c *Color, dst *Chromaticity) Color {
+	src := c.Space.White
+	xyz := c.Convert(xyzSpaceFor(src))
+	m := cat.Matrix(src, dst)
+	return Color{
+		Values: Adapt(&xyz.Values, &m),
+		Space:  xyzSpaceFor(dst),
+		Alpha:  c.Alpha,
+	}
+}
+
+// WhiteBalance treats c as a color seen under the illuminant from and returns
+// the corresponding color under the illuminant to, that is, the color that
+// looks the same to an observer adapted to to as c does to an observer adapted
+// to from. This is the operation that white balancing applies to every pixel
+// of an image. The adaptation uses cat, or [Bradford] if cat is nil, and the
+// result is returned in c's color space.
+func WhiteBalance(c *Color, from, to *Chromaticity, cat *CAT) Color {
+	if cat == nil {
+		cat = Bradford
+	}
+	xyz := c.Convert(XYZ_D65)
+	m := cat.Matrix(from, to)
+	xyz.Values = Adapt(&xyz.Values, &m)
+	return xyz.Convert(c.Space)
+}
+
+// xyzSpaces caches the XYZ spaces returned by xyzSpaceFor, keyed by
+// Chromaticity.
+var xyzSpaces sync.Map
+
+// xyzSpaceFor returns an XYZ space with the given white point. Repeated calls
+// with the same white point return the same space.
+func xyzSpaceFor(white *Chromaticity) *Space {
+	switch *white {
+	case *XYZ_D65.White:
+		return XYZ_D65
+	case *XYZ_D50.White:
+		return XYZ_D50
+	}
+	if cs, ok := xyzSpaces.Load(*white); ok {
+		return cs.(*Space)
+	}
+	w := *white
+	cs := NewXYZSpace("XYZ "+w.String(), fmt.Sprintf("xyz-%g-%g", w.X, w.Y), &w)
+	actual, _ := xyzSpaces.LoadOrStore(w, cs)
+	return actual.(*Space)
+}
+
+// Matrix returns the matrix for adapting XYZ values from the white point src to
+// the white point dst, to be used with [Adapt]. A CAT isn't tied to a direction;
+// the opposite adaptation is simply cat.Matrix(dst, src).
 func (cat *CAT) Matrix(src, dst *Chromaticity) [3][3]float64 {
 	ws := src.XYZ()
 	wd := dst.XYZ()
 
-	coneS := mulVecMat(&ws, &cat.ToCone)
-	coneD := mulVecMat(&wd, &cat.ToCone)
+	coneS := MulVecMat(&ws, &cat.ToCone)
+	coneD := MulVecMat(&wd, &cat.ToCone)
 
 	ρS := coneS[0]
 	γS := coneS[1]
@@ -172,9 +342,9 @@ func (cat *CAT) Matrix(src, dst *Chromaticity) [3][3]float64 {
 		{d * rρ, e * rγ, f * rβ},
 		{g * rρ, h * rγ, i * rβ},
 	}
-	return mulMatMat(&m_, &cat.ToCone)
+	return MulMatMat(&m_, &cat.ToCone)
 }
 
 func Adapt(xyz *[3]float64, m *[3][3]float64) [3]float64 {
-	return mulVecMat(xyz, m)
+	return MulVecMat(xyz, m)
 }
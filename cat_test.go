@@ -0,0 +1,287 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWhites(t *testing.T) {
+	whites := map[string]*Chromaticity{
+		"WhitesCIE2004TwoDegA":      WhitesCIE2004TwoDegA,
+		"WhitesCIE2004TwoDegC":      WhitesCIE2004TwoDegC,
+		"WhitesCIE2004TwoDegD50":    WhitesCIE2004TwoDegD50,
+		"WhitesCIE2004TwoDegD55":    WhitesCIE2004TwoDegD55,
+		"WhitesCIE2004TwoDegD65":    WhitesCIE2004TwoDegD65,
+		"WhitesCIE2004TwoDegD75":    WhitesCIE2004TwoDegD75,
+		"WhitesCIE2004TwoDegFL1":    WhitesCIE2004TwoDegFL1,
+		"WhitesCIE2004TwoDegFL2":    WhitesCIE2004TwoDegFL2,
+		"WhitesCIE2004TwoDegFL3":    WhitesCIE2004TwoDegFL3,
+		"WhitesCIE2004TwoDegFL3_1":  WhitesCIE2004TwoDegFL3_1,
+		"WhitesCIE2004TwoDegFL3_2":  WhitesCIE2004TwoDegFL3_2,
+		"WhitesCIE2004TwoDegFL3_3":  WhitesCIE2004TwoDegFL3_3,
+		"WhitesCIE2004TwoDegFL3_4":  WhitesCIE2004TwoDegFL3_4,
+		"WhitesCIE2004TwoDegFL3_5":  WhitesCIE2004TwoDegFL3_5,
+		"WhitesCIE2004TwoDegFL3_6":  WhitesCIE2004TwoDegFL3_6,
+		"WhitesCIE2004TwoDegFL3_7":  WhitesCIE2004TwoDegFL3_7,
+		"WhitesCIE2004TwoDegFL3_8":  WhitesCIE2004TwoDegFL3_8,
+		"WhitesCIE2004TwoDegFL3_9":  WhitesCIE2004TwoDegFL3_9,
+		"WhitesCIE2004TwoDegFL3_10": WhitesCIE2004TwoDegFL3_10,
+		"WhitesCIE2004TwoDegFL3_11": WhitesCIE2004TwoDegFL3_11,
+		"WhitesCIE2004TwoDegFL3_12": WhitesCIE2004TwoDegFL3_12,
+		"WhitesCIE2004TwoDegFL3_13": WhitesCIE2004TwoDegFL3_13,
+		"WhitesCIE2004TwoDegFL3_14": WhitesCIE2004TwoDegFL3_14,
+		"WhitesCIE2004TwoDegFL3_15": WhitesCIE2004TwoDegFL3_15,
+		"WhitesCIE2004TwoDegFL4":    WhitesCIE2004TwoDegFL4,
+		"WhitesCIE2004TwoDegFL5":    WhitesCIE2004TwoDegFL5,
+		"WhitesCIE2004TwoDegFL6":    WhitesCIE2004TwoDegFL6,
+		"WhitesCIE2004TwoDegFL7":    WhitesCIE2004TwoDegFL7,
+		"WhitesCIE2004TwoDegFL8":    WhitesCIE2004TwoDegFL8,
+		"WhitesCIE2004TwoDegFL9":    WhitesCIE2004TwoDegFL9,
+		"WhitesCIE2004TwoDegFL10":   WhitesCIE2004TwoDegFL10,
+		"WhitesCIE2004TwoDegFL11":   WhitesCIE2004TwoDegFL11,
+		"WhitesCIE2004TwoDegFL12":   WhitesCIE2004TwoDegFL12,
+		"WhitesCIE2004TwoDegHP1":    WhitesCIE2004TwoDegHP1,
+		"WhitesCIE2004TwoDegHP2":    WhitesCIE2004TwoDegHP2,
+		"WhitesCIE2004TwoDegHP3":    WhitesCIE2004TwoDegHP3,
+		"WhitesCIE2004TwoDegHP4":    WhitesCIE2004TwoDegHP4,
+		"WhitesCIE2004TwoDegHP5":    WhitesCIE2004TwoDegHP5,
+		"WhitesCIE2004TenDegA":      WhitesCIE2004TenDegA,
+		"WhitesCIE2004TenDegC":      WhitesCIE2004TenDegC,
+		"WhitesCIE2004TenDegD50":    WhitesCIE2004TenDegD50,
+		"WhitesCIE2004TenDegD55":    WhitesCIE2004TenDegD55,
+		"WhitesCIE2004TenDegD65":    WhitesCIE2004TenDegD65,
+		"WhitesCIE2004TenDegD75":    WhitesCIE2004TenDegD75,
+		"WhitesCSSD50":              WhitesCSSD50,
+		"WhitesSRGBD65":             WhitesSRGBD65,
+	}
+	for name, chr := range whites {
+		if !(chr.X > 0 && chr.X < 1 && chr.Y > 0 && chr.Y < 1 && chr.X+chr.Y < 1) {
+			t.Errorf("%s: %v is not a valid chromaticity", name, *chr)
+		}
+	}
+}
+
+func TestMakeCIEDaylightIlluminantErr(t *testing.T) {
+	for _, temp := range []float64{4000, 7000, 25_000} {
+		got, err := MakeCIEDaylightIlluminantErr(temp)
+		if err != nil {
+			t.Errorf("%g K: unexpected error: %s", temp, err)
+		}
+		if want := MakeCIEDaylightIlluminant(temp); got != want {
+			t.Errorf("%g K: got %v, want %v", temp, got, want)
+		}
+	}
+
+	// The nominal 6504 K should be close to D65.
+	d65, err := MakeCIEDaylightIlluminantErr(6504)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(d65.X-WhitesCIE2004TwoDegD65.X) > 1e-4 || math.Abs(d65.Y-WhitesCIE2004TwoDegD65.Y) > 1e-4 {
+		t.Errorf("got %v, want approximately %v", d65, *WhitesCIE2004TwoDegD65)
+	}
+
+	for _, temp := range []float64{0, 3999.9, 25_000.1, math.NaN(), math.Inf(1)} {
+		if _, err := MakeCIEDaylightIlluminantErr(temp); err == nil {
+			t.Errorf("%g K: expected error", temp)
+		}
+	}
+}
+
+func TestCATs(t *testing.T) {
+	chromaticity := func(xyz [3]float64) *Chromaticity {
+		sum := xyz[0] + xyz[1] + xyz[2]
+		return &Chromaticity{xyz[0] / sum, xyz[1] / sum}
+	}
+	d65 := chromaticity([3]float64{0.95047, 1, 1.08883})
+	d50 := chromaticity([3]float64{0.96422, 1, 0.82521})
+
+	// Every CAT maps the source white to the destination white.
+	for _, cat := range CATs() {
+		src := d65.XYZ()
+		want := d50.XYZ()
+		got := cat.Adapt(&src, d65, d50)
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-12 {
+				t.Errorf("%s: adapted white is %v, want %v", cat.Name, got, want)
+				break
+			}
+		}
+	}
+
+	// D65 to D50 matrices published by Bruce Lindbloom at
+	// http://www.brucelindbloom.com/index.html?Eqn_ChromAdapt.html
+	published := map[*CAT][3][3]float64{
+		Bradford: {
+			{1.0478112, 0.0228866, -0.0501270},
+			{0.0295424, 0.9904844, -0.0170491},
+			{-0.0092345, 0.0150436, 0.7521316},
+		},
+		VonKries: {
+			{1.0160803, 0.0552297, -0.0521326},
+			{0.0060666, 0.9955661, -0.0012235},
+			{0.0000000, 0.0000000, 0.7578869},
+		},
+		XYZScaling: {
+			{1.0144665, 0.0000000, 0.0000000},
+			{0.0000000, 1.0000000, 0.0000000},
+			{0.0000000, 0.0000000, 0.7578869},
+		},
+	}
+outer:
+	for cat, want := range published {
+		got := cat.Matrix(d65, d50)
+		for i := range got {
+			for j := range got[i] {
+				if math.Abs(got[i][j]-want[i][j]) > 1e-6 {
+					t.Errorf("%s: got matrix %v, want %v", cat.Name, got, want)
+					continue outer
+				}
+			}
+		}
+	}
+}
+
+func TestNewCAT(t *testing.T) {
+	cat := NewCAT(Bradford.ToCone)
+	for i := range cat.FromCone {
+		for j := range cat.FromCone[i] {
+			if math.Abs(cat.FromCone[i][j]-Bradford.FromCone[i][j]) > 1e-15 {
+				t.Fatalf("got FromCone %v, want %v", cat.FromCone, Bradford.FromCone)
+			}
+		}
+	}
+
+	for _, cat := range CATs() {
+		if err := cat.Validate(); err != nil {
+			t.Errorf("%s: %s", cat.Name, err)
+		}
+	}
+
+	bad := *Bradford
+	bad.FromCone[1][2] = 0.0049291228212855594
+	if bad.Validate() == nil {
+		t.Errorf("expected error for mistyped matrix")
+	}
+}
+
+func TestInvertMat3(t *testing.T) {
+	m := [3][3]float64{
+		{1, 2, 3},
+		{0, 1, 4},
+		{5, 6, 0},
+	}
+	want := [3][3]float64{
+		{-24, 18, 5},
+		{20, -15, -4},
+		{-5, 4, 1},
+	}
+	got, ok := InvertMat3(&m)
+	if !ok {
+		t.Fatal("matrix reported as singular")
+	}
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	singular := [3][3]float64{
+		{2, 0, 1},
+		{1, 3, 2},
+		{1, 1, 1},
+	}
+	if _, ok := InvertMat3(&singular); ok {
+		t.Errorf("singular matrix reported as invertible")
+	}
+}
+
+func TestAdaptRoundTrip(t *testing.T) {
+	xyz := [3]float64{0.3, 0.5, 0.2}
+	for _, cat := range CATs() {
+		m := cat.Matrix(WhitesCIE2004TwoDegD65, WhitesCIE2004TwoDegA)
+		adapted := Adapt(&xyz, &m)
+
+		inv, _ := InvertMat3(&m)
+		back := cat.Matrix(WhitesCIE2004TwoDegA, WhitesCIE2004TwoDegD65)
+		for _, m := range []*[3][3]float64{&inv, &back} {
+			got := Adapt(&adapted, m)
+			for i := range got {
+				if math.Abs(got[i]-xyz[i]) > 1e-12 {
+					t.Errorf("%s: got %v, want %v", cat.Name, got, xyz)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestAdaptColor(t *testing.T) {
+	gray := Make(SRGB, 0.5, 0.5, 0.5, 0.8)
+	xyz := gray.Convert(XYZ_D65)
+	for _, cat := range CATs() {
+		got := cat.AdaptColor(&gray, WhitesCSSD50)
+		if got.Space != XYZ_D50 || got.Alpha != gray.Alpha {
+			t.Errorf("%s: got %v, want a color in %s with alpha %g", cat.Name, got, XYZ_D50.Name, gray.Alpha)
+		}
+		m := cat.Matrix(SRGB.White, WhitesCSSD50)
+		if want := Adapt(&xyz.Values, &m); !approxValues(got.Values, want, 1e-12) {
+			t.Errorf("%s: got %v, want %v", cat.Name, got.Values, want)
+		}
+		// A neutral color takes on the chromaticity of the new white point.
+		if chr := ChromaticityFromXYZ(got.Values); !chr.ApproxEqual(*WhitesCSSD50, 1e-9) {
+			t.Errorf("%s: got chromaticity %v, want %v", cat.Name, chr, *WhitesCSSD50)
+		}
+	}
+
+	// The color space tree uses Bradford to relate D50 and D65.
+	got := Bradford.AdaptColor(&gray, WhitesCSSD50)
+	if want := gray.Convert(XYZ_D50); !approxValues(got.Values, want.Values, 1e-12) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Adapting to an arbitrary white point uses a new XYZ space, and colors in
+	// D50 spaces start from D50.
+	lab := Make(Lab, 50, 10, -20, 1)
+	got = CAT16.AdaptColor(&lab, WhitesCIE2004TwoDegA)
+	if *got.Space.White != *WhitesCIE2004TwoDegA {
+		t.Errorf("got white point %v, want %v", got.Space.White, *WhitesCIE2004TwoDegA)
+	}
+	a := *WhitesCIE2004TwoDegA
+	if again := Bradford.AdaptColor(&gray, &a); again.Space != got.Space {
+		t.Errorf("adapting to the same white point twice used different spaces")
+	}
+	m := CAT16.Matrix(WhitesCSSD50, WhitesCIE2004TwoDegA)
+	labXYZ := lab.Convert(XYZ_D50)
+	if want := Adapt(&labXYZ.Values, &m); !approxValues(got.Values, want, 1e-12) {
+		t.Errorf("got %v, want %v", got.Values, want)
+	}
+}
+
+func TestWhiteBalance(t *testing.T) {
+	gray := Make(SRGB, 0.5, 0.5, 0.5, 1)
+	for _, cat := range append(CATs(), nil) {
+		got := WhiteBalance(&gray, WhitesSRGBD65, WhitesCSSD50, cat)
+		if got.Space != SRGB || got.Alpha != gray.Alpha {
+			t.Errorf("got %v, want a color in %s with alpha %g", got, SRGB.Name, gray.Alpha)
+		}
+		// The neutral gray under D65 becomes a neutral gray under D50, which
+		// has D50's chromaticity and the same luminance.
+		xyz := got.Convert(XYZ_D65)
+		if chr := ChromaticityFromXYZ(xyz.Values); !chr.ApproxEqual(*WhitesCSSD50, 1e-9) {
+			t.Errorf("got chromaticity %v, want %v", chr, *WhitesCSSD50)
+		}
+		if want := gray.Convert(XYZ_D65).Values[1]; math.Abs(xyz.Values[1]-want) > 1e-2 {
+			t.Errorf("got luminance %g, want approximately %g", xyz.Values[1], want)
+		}
+	}
+
+	// Balancing to the same illuminant is the identity, and balancing back
+	// undoes the adaptation.
+	c := Make(Oklch, 0.6, 0.12, 40, 1)
+	if got := WhiteBalance(&c, WhitesSRGBD65, WhitesSRGBD65, nil); !approxValues(got.Values, c.Values, 1e-9) {
+		t.Errorf("got %v, want %v", got, c)
+	}
+	warm := WhiteBalance(&c, WhitesSRGBD65, WhitesCIE2004TwoDegA, CAT16)
+	if got := WhiteBalance(&warm, WhitesCIE2004TwoDegA, WhitesSRGBD65, CAT16); !approxValues(got.Values, c.Values, 1e-9) {
+		t.Errorf("got %v after round trip, want %v", got, c)
+	}
+}
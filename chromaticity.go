@@ -0,0 +1,222 @@
+package color
+
+import (
+	"fmt"
+	"math"
+)
+
+// robertsonIsotherms is the table of isotemperature lines used by Robertson's
+// method of computing correlated color temperatures, as published in
+// Wyszecki & Stiles, "Color Science" (1982). Each entry consists of the
+// reciprocal temperature in 1/MK, the u and v coordinates in the CIE 1960 UCS
+// of the point where the line intersects the Planckian locus, and the slope of
+// the line.
+var robertsonIsotherms = [...][4]float64{
+	{0, 0.18006, 0.26352, -0.24341},
+	{10, 0.18066, 0.26589, -0.25479},
+	{20, 0.18133, 0.26846, -0.26876},
+	{30, 0.18208, 0.27119, -0.28539},
+	{40, 0.18293, 0.27407, -0.30470},
+	{50, 0.18388, 0.27709, -0.32675},
+	{60, 0.18494, 0.28021, -0.35156},
+	{70, 0.18611, 0.28342, -0.37915},
+	{80, 0.18740, 0.28668, -0.40955},
+	{90, 0.18880, 0.28997, -0.44278},
+	{100, 0.19032, 0.29326, -0.47888},
+	{125, 0.19462, 0.30141, -0.58204},
+	{150, 0.19962, 0.30921, -0.70471},
+	{175, 0.20525, 0.31647, -0.84901},
+	{200, 0.21142, 0.32312, -1.0182},
+	{225, 0.21807, 0.32909, -1.2168},
+	{250, 0.22511, 0.33439, -1.4512},
+	{275, 0.23247, 0.33904, -1.7298},
+	{300, 0.24010, 0.34308, -2.0637},
+	{325, 0.24792, 0.34655, -2.4681},
+	{350, 0.25591, 0.34951, -2.9641},
+	{375, 0.26400, 0.35200, -3.5814},
+	{400, 0.27218, 0.35407, -4.3633},
+	{425, 0.28039, 0.35577, -5.3762},
+	{450, 0.28863, 0.35714, -6.7262},
+	{475, 0.29685, 0.35823, -8.5955},
+	{500, 0.30505, 0.35907, -11.324},
+	{525, 0.31320, 0.35968, -15.628},
+	{550, 0.32129, 0.36011, -23.325},
+	{575, 0.32931, 0.36038, -40.770},
+	{600, 0.33724, 0.36051, -116.45},
+}
+
+// UV returns the chromaticity's u' and v' coordinates in the CIE 1976 UCS
+// (uniform chromaticity scale) diagram, which is also the basis of CIELUV. For
+// the degenerate chromaticity where the conversion is undefined, it returns
+// (0, 0).
+func (chr *Chromaticity) UV() (u, v float64) {
+	d := -2*chr.X + 12*chr.Y + 3
+	if d == 0 {
+		return 0, 0
+	}
+	return 4 * chr.X / d, 9 * chr.Y / d
+}
+
+// ChromaticityFromUV returns the xy chromaticity of the u' and v' coordinates
+// in the CIE 1976 UCS diagram. It is the inverse of [Chromaticity.UV].
+func ChromaticityFromUV(u, v float64) Chromaticity {
+	d := 6*u - 16*v + 12
+	if d == 0 {
+		return Chromaticity{0, 0}
+	}
+	return Chromaticity{9 * u / d, 4 * v / d}
+}
+
+// ChromaticityFromXYZ returns the xy chromaticity of the XYZ tristimulus
+// values xyz. It is the inverse of [Chromaticity.XYZ], up to the luminance,
+// which chromaticities don't describe. The chromaticity of black, whose
+// coordinates sum to zero, is undefined; ChromaticityFromXYZ returns (0, 0)
+// for it.
+func ChromaticityFromXYZ(xyz [3]float64) Chromaticity {
+	sum := xyz[0] + xyz[1] + xyz[2]
+	if sum == 0 {
+		return Chromaticity{0, 0}
+	}
+	return Chromaticity{xyz[0] / sum, xyz[1] / sum}
+}
+
+// uv1960 returns the chromaticity's coordinates in the CIE 1960 UCS.
+func (chr *Chromaticity) uv1960() (u, v float64) {
+	u, v = chr.UV()
+	return u, v * 2 / 3
+}
+
+// planckianUV1960 approximates the coordinates of the Planckian locus at the
+// given temperature in the CIE 1960 UCS, using the approximation by Krystek,
+// "An algorithm to calculate correlated colour temperature" (1985). It is
+// accurate to within 1e-4 for temperatures between 1000 K and 15,000 K.
+func planckianUV1960(temp float64) (u, v float64) {
+	t := temp
+	u = (0.860117757 + 1.54118254e-4*t + 1.28641212e-7*t*t) /
+		(1 + 8.42420235e-4*t + 7.08145163e-7*t*t)
+	v = (0.317398726 + 4.22806245e-5*t + 4.20481691e-8*t*t) /
+		(1 - 2.89741816e-5*t + 1.61456053e-7*t*t)
+	return u, v
+}
+
+// MakePlanckianLocus computes the chromaticity of an ideal blackbody radiator
+// (that is, a point on the Planckian locus) at the specified temperature in
+// Kelvin. It uses the rational approximation of the locus in the CIE 1960 UCS
+// by Krystek, which is accurate to within 1e-4 of the exact locus for the CIE
+// 1931 standard observer.
+//
+// The temperature must be between 1000 K and 15,000 K.
+func MakePlanckianLocus(temp float64) Chromaticity {
+	if !(temp >= 1000 && temp <= 15_000) {
+		panic(fmt.Sprintf("color temperature %v is not in range [1000, 15000]", temp))
+	}
+	u, v := planckianUV1960(temp)
+	d := 2*u - 8*v + 4
+	return Chromaticity{3 * u / d, 2 * v / d}
+}
+
+// CCT estimates the correlated color temperature, in Kelvin, of the
+// chromaticity, using Robertson's method. It also returns Duv, the signed
+// distance of the chromaticity from the Planckian (blackbody) locus in the
+// CIE 1960 UCS. Positive values of Duv denote chromaticities above the locus
+// (towards green), negative values denote chromaticities below the locus
+// (towards magenta).
+//
+// The correlated color temperature is only meaningful for chromaticities close
+// to the Planckian locus, typically with |Duv| < 0.05. Robertson's method
+// supports temperatures of approximately 1667 K and higher; for chromaticities
+// outside that range, the returned temperature is NaN. Duv is computed using
+// an approximation of the Planckian locus that is accurate between 1000 K and
+// 15,000 K.
+func (chr *Chromaticity) CCT() (cct float64, duv float64) {
+	us, vs := chr.uv1960()
+
+	var di, dm float64
+	i := 0
+	for ; i < len(robertsonIsotherms); i++ {
+		iso := &robertsonIsotherms[i]
+		di = (vs - iso[2]) - iso[3]*(us-iso[1])
+		if i > 0 && ((di < 0 && dm >= 0) || (di >= 0 && dm < 0)) {
+			break
+		}
+		dm = di
+	}
+	if i == len(robertsonIsotherms) {
+		return math.NaN(), math.NaN()
+	}
+
+	prev := &robertsonIsotherms[i-1]
+	cur := &robertsonIsotherms[i]
+	dm /= math.Sqrt(1 + prev[3]*prev[3])
+	di /= math.Sqrt(1 + cur[3]*cur[3])
+	p := dm / (dm - di)
+	cct = 1e6 / lerp(prev[0], cur[0], p)
+
+	up, vp := planckianUV1960(cct)
+	duv = math.Hypot(us-up, vs-vp)
+	if vs < vp {
+		duv = -duv
+	}
+	return cct, duv
+}
+
+// Duv returns the signed distance of the chromaticity from the Planckian locus
+// in the CIE 1960 UCS, which describes a light's green or magenta tint.
+// Positive values denote chromaticities above the locus (towards green),
+// negative values chromaticities below it (towards magenta). It is the same
+// value as returned by [Chromaticity.CCT], and like it, is NaN for
+// chromaticities whose color temperature can't be determined.
+func (chr *Chromaticity) Duv() float64 {
+	_, duv := chr.CCT()
+	return duv
+}
+
+// spectralLocus is the polygon in the xy chromaticity diagram formed by the
+// chromaticities of monochromatic light, as seen by the CIE 1931 standard
+// observer at 10 nm intervals, and closed by the line of purples.
+var spectralLocus = func() []Chromaticity {
+	out := make([]Chromaticity, 0, len(cmfCIE1931TwoDeg))
+	for _, xyz := range cmfCIE1931TwoDeg {
+		out = append(out, ChromaticityFromXYZ(xyz))
+	}
+	return out
+}()
+
+// insideSpectralLocus reports whether chr lies inside the polygon formed by
+// the spectral locus and the line of purples.
+func insideSpectralLocus(chr Chromaticity) bool {
+	// Even-odd rule, casting a ray in the +x direction.
+	inside := false
+	for i := range spectralLocus {
+		a := spectralLocus[i]
+		b := spectralLocus[(i+1)%len(spectralLocus)]
+		if (a.Y > chr.Y) != (b.Y > chr.Y) {
+			x := a.X + (chr.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if chr.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// IsRealColor reports whether c is physically realizable, that is, whether its
+// chromaticity lies within the area enclosed by the spectral locus and the
+// line of purples. Colors outside of it, such as the primaries of some wide
+// gamut color spaces, are imaginary: they can be expressed numerically but
+// don't correspond to any light. Black is considered real, and colors with
+// negative luminance are not.
+//
+// The spectral locus is approximated by the chromaticities of monochromatic
+// light at 10 nm intervals, which places colors that are extremely close to it
+// outside.
+func (c *Color) IsRealColor() bool {
+	xyz := c.Convert(XYZ_D65).Values
+	switch {
+	case xyz[1] < 0:
+		return false
+	case xyz[0]+xyz[1]+xyz[2] == 0:
+		return xyz == [3]float64{}
+	}
+	return insideSpectralLocus(ChromaticityFromXYZ(xyz))
+}
@@ -0,0 +1,207 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCCT(t *testing.T) {
+	tests := []struct {
+		chr *Chromaticity
+		cct float64
+		duv float64
+	}{
+		// Values from the CIE 15:2004 and CIE 1931 tables.
+		{WhitesSRGBD65, 6504, 0.0032},
+		{WhitesCIE2004TwoDegD50, 5003, 0.0033},
+		{WhitesCIE2004TwoDegA, 2856, 0},
+	}
+	for _, tt := range tests {
+		cct, duv := tt.chr.CCT()
+		if math.Abs(cct-tt.cct) > 5 {
+			t.Errorf("%v: got CCT %g, want %g", *tt.chr, cct, tt.cct)
+		}
+		if math.Abs(duv-tt.duv) > 0.0005 {
+			t.Errorf("%v: got Duv %g, want %g", *tt.chr, duv, tt.duv)
+		}
+	}
+
+	// Far too red to have a meaningful color temperature
+	if cct, _ := (&Chromaticity{0.7, 0.3}).CCT(); !math.IsNaN(cct) {
+		t.Errorf("got CCT %g, want NaN", cct)
+	}
+}
+
+func TestDuv(t *testing.T) {
+	for _, temp := range []float64{2000, 3000, 5000, 6500, 10000} {
+		chr := MakePlanckianLocus(temp)
+		if duv := chr.Duv(); math.Abs(duv) > 1e-4 {
+			t.Errorf("%g K: got Duv %g, want approximately 0", temp, duv)
+		}
+	}
+
+	// Cool white fluorescent light has a slight green tint.
+	if duv := WhitesCIE2004TwoDegFL2.Duv(); !(duv > 0.001 && duv < 0.005) {
+		t.Errorf("FL2: got Duv %g, want a small positive value", duv)
+	}
+	// Moving towards magenta makes Duv negative.
+	below := Chromaticity{0.3127, 0.3100}
+	if duv := below.Duv(); !(duv < -0.005) {
+		t.Errorf("%v: got Duv %g, want a negative value", below, duv)
+	}
+
+	if _, want := WhitesSRGBD65.CCT(); WhitesSRGBD65.Duv() != want {
+		t.Errorf("got Duv %g, want %g like CCT", WhitesSRGBD65.Duv(), want)
+	}
+}
+
+func TestMakePlanckianLocus(t *testing.T) {
+	// Points on the Planckian locus for the CIE 1931 2° observer.
+	tests := []struct {
+		temp float64
+		want Chromaticity
+	}{
+		{2000, Chromaticity{0.5267, 0.4133}},
+		{2856, Chromaticity{0.4476, 0.4074}},
+		{5000, Chromaticity{0.3451, 0.3516}},
+		{6500, Chromaticity{0.3135, 0.3237}},
+		{10000, Chromaticity{0.2807, 0.2884}},
+	}
+	for _, tt := range tests {
+		got := MakePlanckianLocus(tt.temp)
+		if math.Abs(got.X-tt.want.X) > 5e-4 || math.Abs(got.Y-tt.want.Y) > 5e-4 {
+			t.Errorf("%g K: got %v, want %v", tt.temp, got, tt.want)
+		}
+		if cct, duv := got.CCT(); math.Abs(cct-tt.temp) > tt.temp*3e-3 || math.Abs(duv) > 1e-4 {
+			t.Errorf("%g K: got CCT %g and Duv %g", tt.temp, cct, duv)
+		}
+	}
+}
+
+func TestUV(t *testing.T) {
+	u, v := WhitesSRGBD65.UV()
+	if math.Abs(u-0.1978) > 1e-4 || math.Abs(v-0.4683) > 1e-4 {
+		t.Errorf("got (%g, %g), want (0.1978, 0.4683)", u, v)
+	}
+
+	for _, chr := range []*Chromaticity{WhitesSRGBD65, WhitesCSSD50, WhitesCIE2004TwoDegFL2, {0.64, 0.33}} {
+		u, v := chr.UV()
+		got := ChromaticityFromUV(u, v)
+		if math.Abs(got.X-chr.X) > 1e-12 || math.Abs(got.Y-chr.Y) > 1e-12 {
+			t.Errorf("%v didn't round-trip, got %v", *chr, got)
+		}
+	}
+
+	// Degenerate inputs mustn't produce NaNs or infinities.
+	if u, v := (&Chromaticity{1.5, 0}).UV(); u != 0 || v != 0 {
+		t.Errorf("got (%g, %g), want (0, 0)", u, v)
+	}
+	if got := ChromaticityFromUV(0, 0.75); got != (Chromaticity{}) {
+		t.Errorf("got %v, want (0, 0)", got)
+	}
+}
+
+func TestChromaticityString(t *testing.T) {
+	tests := []struct {
+		chr  Chromaticity
+		want string
+	}{
+		{*WhitesSRGBD65, "xy(0.3127, 0.3290)"},
+		{*WhitesCIE2004TwoDegD65, "xy(0.3127, 0.3290)"},
+		{Chromaticity{0.64, 0.33}, "xy(0.6400, 0.3300)"},
+		{Chromaticity{}, "xy(0.0000, 0.0000)"},
+	}
+	for _, tt := range tests {
+		if got := tt.chr.String(); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+	if got, want := fmt.Sprint(WhitesSRGBD65), "xy(0.3127, 0.3290)"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestChromaticityApproxEqual(t *testing.T) {
+	d65 := *WhitesSRGBD65
+	tests := []struct {
+		other Chromaticity
+		tol   float64
+		want  bool
+	}{
+		{d65, 0, true},
+		{*WhitesCIE2004TwoDegD65, 1e-4, true},
+		{*WhitesCIE2004TwoDegD65, 1e-5, false},
+		{Chromaticity{0.3128, 0.3289}, 2e-4, true},
+		{Chromaticity{0.3127, 0.3295}, 2e-4, false},
+		{*WhitesCSSD50, 1e-2, false},
+	}
+	for _, tt := range tests {
+		if got := d65.ApproxEqual(tt.other, tt.tol); got != tt.want {
+			t.Errorf("%v.ApproxEqual(%v, %g) = %t, want %t", d65, tt.other, tt.tol, got, tt.want)
+		}
+	}
+}
+
+func TestChromaticityFromXYZ(t *testing.T) {
+	for _, chr := range []*Chromaticity{WhitesSRGBD65, WhitesCSSD50, WhitesCIE2004TwoDegA, {0.64, 0.33}, {0.15, 0.06}} {
+		got := ChromaticityFromXYZ(chr.XYZ())
+		if math.Abs(got.X-chr.X) > 1e-12 || math.Abs(got.Y-chr.Y) > 1e-12 {
+			t.Errorf("%v didn't round-trip, got %v", *chr, got)
+		}
+	}
+
+	// Scaling the luminance doesn't change the chromaticity.
+	xyz := WhitesSRGBD65.XYZ()
+	for i := range xyz {
+		xyz[i] *= 0.25
+	}
+	if got := ChromaticityFromXYZ(xyz); math.Abs(got.X-WhitesSRGBD65.X) > 1e-12 || math.Abs(got.Y-WhitesSRGBD65.Y) > 1e-12 {
+		t.Errorf("got %v, want %v", got, *WhitesSRGBD65)
+	}
+
+	if got := ChromaticityFromXYZ([3]float64{}); got != (Chromaticity{}) {
+		t.Errorf("got %v for black, want (0, 0)", got)
+	}
+}
+
+func TestIsRealColor(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want bool
+	}{
+		{Make(SRGB, 1, 0, 0, 1), true},
+		{Make(SRGB, 0, 1, 0, 1), true},
+		{Make(SRGB, 0, 0, 1, 1), true},
+		{Make(SRGB, 1, 1, 1, 1), true},
+		{Make(SRGB, 0, 0, 0, 1), true},
+		{Make(DisplayP3, 0, 1, 0, 1), true},
+		{Make(Oklch, 0.7, 0.1, 120, 1), true},
+		// ProPhoto's green and blue primaries are imaginary.
+		{Make(LinearProPhoto, 0, 1, 0, 1), false},
+		{Make(LinearProPhoto, 0, 0, 1, 1), false},
+		{Make(XYZ_D65, 0, 1, 0, 1), false},
+		{Make(XYZ_D65, 1, 0, 0, 1), false},
+		{Make(XYZ_D65, 0.5, -0.1, 0.5, 1), false},
+		{Make(SRGB, -0.5, 1, 0, 1), true},
+		{Make(SRGB, -2, 1, -2, 1), false},
+	}
+	for _, tt := range tests {
+		if got := tt.c.IsRealColor(); got != tt.want {
+			t.Errorf("%v: got %t, want %t", tt.c, got, tt.want)
+		}
+	}
+
+	// Chromaticities slightly inside the spectral locus are real.
+	for _, xyz := range cmfCIE1931TwoDeg[2:30] {
+		white := XYZ_D65.White.XYZ()
+		var v [3]float64
+		for i := range v {
+			v[i] = 0.95*xyz[i] + 0.05*white[i]*xyz[1]
+		}
+		c := Make(XYZ_D65, v[0], v[1], v[2], 1)
+		if !c.IsRealColor() {
+			t.Errorf("%v: got false, want true", c)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package color
+
+// CMYK converts c to sRGB and returns its cyan, magenta, yellow, and key
+// (black) components, each in the range [0, 1]. Out-of-gamut colors are
+// clipped to the sRGB gamut first.
+//
+// This uses the naive conversion without any undercolor removal or gray
+// component replacement, treating CMYK as a simple transformation of sRGB. It
+// doesn't model any real inks or printing processes and is not a substitute
+// for ICC-based color management.
+func (c *Color) CMYK() (cmyk [4]float64) {
+	rgb := GamutClip(c, SRGB)
+	r, g, b := rgb.Values[0], rgb.Values[1], rgb.Values[2]
+	k := 1 - max(r, g, b)
+	if k == 1 {
+		return [4]float64{0, 0, 0, 1}
+	}
+	return [4]float64{
+		(1 - r - k) / (1 - k),
+		(1 - g - k) / (1 - k),
+		(1 - b - k) / (1 - k),
+		k,
+	}
+}
+
+// FromCMYK returns the opaque sRGB color described by the cyan, magenta,
+// yellow, and key (black) components, each in the range [0, 1]. It is the
+// inverse of [Color.CMYK] and has the same limitations.
+func FromCMYK(c, m, y, k float64) Color {
+	return Make(SRGB,
+		(1-c)*(1-k),
+		(1-m)*(1-k),
+		(1-y)*(1-k),
+		1,
+	)
+}
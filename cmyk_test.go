@@ -0,0 +1,32 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCMYK(t *testing.T) {
+	tests := []struct {
+		rgb  Color
+		cmyk [4]float64
+	}{
+		{Make(SRGB, 0, 1, 1, 1), [4]float64{1, 0, 0, 0}},
+		{Make(SRGB, 1, 0, 1, 1), [4]float64{0, 1, 0, 0}},
+		{Make(SRGB, 1, 1, 0, 1), [4]float64{0, 0, 1, 0}},
+		{Make(SRGB, 0, 0, 0, 1), [4]float64{0, 0, 0, 1}},
+		{Make(SRGB, 1, 1, 1, 1), [4]float64{0, 0, 0, 0}},
+		{Make(SRGB, 0.5, 0.25, 0, 1), [4]float64{0, 0.5, 1, 0.5}},
+	}
+	for _, tt := range tests {
+		if got := tt.rgb.CMYK(); got != tt.cmyk {
+			t.Errorf("%v: got %v, want %v", tt.rgb, got, tt.cmyk)
+		}
+		got := FromCMYK(tt.cmyk[0], tt.cmyk[1], tt.cmyk[2], tt.cmyk[3])
+		for i := range got.Values {
+			if math.Abs(got.Values[i]-tt.rgb.Values[i]) > 1e-12 {
+				t.Errorf("%v: got %v, want %v", tt.cmyk, got, tt.rgb)
+				break
+			}
+		}
+	}
+}
@@ -115,7 +115,8 @@
 // tradeoffs.
 //
 // [Step] creates color gradients by linearly interpolating between two colors
-// in a color space of your choice.
+// in a color space of your choice. [StepFunc] and [StepHue] additionally
+// control the easing and the interpolation of hues.
 //
 // [CSS Color Module Level 4]: https://www.w3.org/TR/css-color-4/
 // [white point]: https://en.wikipedia.org/wiki/White_point
@@ -129,7 +130,6 @@ package color
 // acescc.js
 // acescg.js
 // cam16.js
-// hct.js
 // hpluv.js
 // hsl.js
 // hsluv.js
@@ -145,8 +145,6 @@ package color
 // okhsv.js
 // oklrab.js
 // oklrch.js
-// rec2020-linear.js
-// rec2020.js
 // rec2100-hlg.js
 // rec2100-pq.js
 // xyz-abs-d65.js
@@ -154,6 +152,10 @@ package color
 import (
 	"fmt"
 	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Make is a convenience function for initializing colors.
@@ -170,30 +172,81 @@ func Make(space *Space, p1, p2, p3, alpha float64) Color {
 	}
 }
 
+// Common colors, defined in sRGB. Green is the pure sRGB primary, which CSS
+// calls lime; CSS's green is the darker #008000. Transparent is transparent
+// black, like CSS's transparent.
+var (
+	Black       = Make(SRGB, 0, 0, 0, 1)
+	White       = Make(SRGB, 1, 1, 1, 1)
+	Red         = Make(SRGB, 1, 0, 0, 1)
+	Green       = Make(SRGB, 0, 1, 0, 1)
+	Blue        = Make(SRGB, 0, 0, 1, 1)
+	Cyan        = Make(SRGB, 0, 1, 1, 1)
+	Magenta     = Make(SRGB, 1, 0, 1, 1)
+	Yellow      = Make(SRGB, 1, 1, 0, 1)
+	Transparent = Make(SRGB, 0, 0, 0, 0)
+)
+
 func lerp(x, y float64, a float64) float64 {
 	return x*(1.0-a) + y*a
 }
 
 // Step computes num colors that lie between c1 and c2, interpolating in the in
 // color space and returning them in the out color space, without applying any
-// gamut mapping.
+// gamut mapping. The colors are evenly spaced and include both endpoints: the
+// i-th color is at position i/(num-1), so the first color is c1 and the last
+// is c2, as converted to the out color space.
 func Step(c1, c2 *Color, in, out *Space, num int) iter.Seq[Color] {
+	return StepFunc(c1, c2, in, out, num, EaseLinear)
+}
+
+// StepSlice is like [Step], but returns the colors as a slice.
+func StepSlice(c1, c2 *Color, in, out *Space, num int) []Color {
+	return slices.Collect(Step(c1, c2, in, out, num))
+}
+
+// StepFunc is like [Step], but applies the easing function ease to the
+// position of each step before interpolating. ease maps positions in [0, 1]
+// to interpolation factors and should map 0 to 0 and 1 to 1.
+func StepFunc(c1, c2 *Color, in, out *Space, num int, ease func(t float64) float64) iter.Seq[Color] {
+	return step(c1, c2, in, out, num, interpolation{ease: ease, hue: rawHue})
+}
+
+// StepHue is like [Step], but interpolates the hues of polar color spaces such
+// as [Oklch] along the path around the hue circle described by mode. [Step],
+// in contrast, interpolates hue angles like any other coordinate.
+func StepHue(c1, c2 *Color, in, out *Space, num int, mode HueInterpolation) iter.Seq[Color] {
+	return step(c1, c2, in, out, num, interpolation{ease: EaseLinear, hue: mode})
+}
+
+// StepPremultiplied is like [Step], but interpolates in premultiplied alpha
+// form. That is, the coordinates of the two colors are multiplied by their
+// alpha values before interpolating, and the interpolated coordinates are
+// divided by the interpolated alpha. This avoids the muddy colors that
+// [Step] produces when fading to a transparent color. Hue angles aren't
+// premultiplied.
+func StepPremultiplied(c1, c2 *Color, in, out *Space, num int) iter.Seq[Color] {
+	return step(c1, c2, in, out, num, interpolation{ease: EaseLinear, hue: rawHue, premultiplied: true})
+}
+
+// interpolation describes how to interpolate between two colors.
+type interpolation struct {
+	ease          func(t float64) float64
+	hue           HueInterpolation
+	premultiplied bool
+}
+
+func step(c1, c2 *Color, in, out *Space, num int, interp interpolation) iter.Seq[Color] {
 	if num < 2 {
 		panic("need at least two steps")
 	}
 	return func(yield func(Color) bool) {
-		c1in := c1.Convert(in)
-		c2in := c2.Convert(in)
+		c1in, c2in := prepareInterpolation(c1, c2, in)
+		fixupColorHues(&c1in, &c2in, interp.hue)
 
 		for i := range num {
-			t := float64(i) / float64(num-1)
-			c := Make(
-				in,
-				lerp(c1in.Values[0], c2in.Values[0], t),
-				lerp(c1in.Values[1], c2in.Values[1], t),
-				lerp(c1in.Values[2], c2in.Values[2], t),
-				lerp(c1in.Alpha, c2in.Alpha, t),
-			)
+			t := interp.ease(float64(i) / float64(num-1))
+			c := lerpColor(&c1in, &c2in, t, interp)
 			cout := c.Convert(out)
 			if !yield(cout) {
 				return
@@ -202,6 +255,506 @@ func Step(c1, c2 *Color, in, out *Space, num int) iter.Seq[Color] {
 	}
 }
 
+// Gradient computes num evenly spaced colors along a gradient through the
+// color stops stops, which are located at the given positions. Between
+// adjacent stops, colors are interpolated linearly in the in color space, like
+// [Step] does, and they are returned in the in color space. Positions must be
+// sorted in increasing order and lie in [0, 1]. Positions before the first and
+// after the last stop take the color of that stop. Two stops at the same
+// position create a hard transition, with the position itself taking the
+// color of the latter stop.
+//
+// Gradient panics if there are fewer than two stops, if the numbers of stops
+// and positions differ, or if the positions are invalid.
+func Gradient(stops []Color, positions []float64, in *Space, num int) iter.Seq[Color] {
+	if num < 2 {
+		panic("need at least two steps")
+	}
+	if len(stops) < 2 {
+		panic("need at least two stops")
+	}
+	if len(stops) != len(positions) {
+		panic("stops and positions have different lengths")
+	}
+	for i, pos := range positions {
+		if !(pos >= 0 && pos <= 1) {
+			panic("positions must lie in [0, 1]")
+		}
+		if i > 0 && pos < positions[i-1] {
+			panic("positions must be sorted")
+		}
+	}
+	return func(yield func(Color) bool) {
+		interp := interpolation{ease: EaseLinear, hue: rawHue}
+		seg := 0
+		for i := range num {
+			t := float64(i) / float64(num-1)
+			for seg < len(stops)-2 && t >= positions[seg+1] {
+				seg++
+			}
+			p1, p2 := positions[seg], positions[seg+1]
+			var u float64
+			switch {
+			case t <= p1:
+				u = 0
+			case t >= p2:
+				u = 1
+			default:
+				u = (t - p1) / (p2 - p1)
+			}
+			if !yield(mix(&stops[seg], &stops[seg+1], in, u, interp)) {
+				return
+			}
+		}
+	}
+}
+
+// GradientSlice is like [Gradient], but returns the colors as a slice.
+func GradientSlice(stops []Color, positions []float64, in *Space, num int) []Color {
+	return slices.Collect(Gradient(stops, positions, in, num))
+}
+
+// SplineGradient computes num evenly spaced colors along a smooth curve
+// through the color stops stops, which are spaced evenly along the curve. It
+// interpolates each coordinate and alpha in the in color space using a uniform
+// Catmull-Rom spline, which, unlike the piecewise linear interpolation of
+// [Gradient], has no kinks at the stops. Hue angles are interpolated along the
+// shorter arc between adjacent stops. Colors are returned in the in color
+// space. Alpha is clamped to [0, 1], but because splines can overshoot,
+// coordinates may leave the range spanned by the stops.
+//
+// Missing components are carried over to the in color space like by [Mix] and
+// take the value of the nearest stop that has them.
+//
+// If num-1 is a multiple of len(stops)-1, the curve's samples include the
+// stops themselves.
+func SplineGradient(stops []Color, in *Space, num int) iter.Seq[Color] {
+	if num < 2 {
+		panic("need at least two steps")
+	}
+	if len(stops) < 2 {
+		panic("need at least two stops")
+	}
+	return func(yield func(Color) bool) {
+		pts := make([]Color, len(stops))
+		for i := range stops {
+			pts[i] = carryMissing(&stops[i], in)
+		}
+		fillMissing(pts)
+		// Unwrap hues so that adjacent stops are at most 180° apart.
+		for i, coord := range in.Coords {
+			if !coord.IsAngle {
+				continue
+			}
+			for j := 1; j < len(pts); j++ {
+				prev := pts[j-1].Values[i]
+				pts[j].Values[i] = prev + HueDifference(prev, pts[j].Values[i])
+			}
+		}
+
+		n := len(pts)
+		for i := range num {
+			pos := float64(i) / float64(num-1) * float64(n-1)
+			seg := min(int(pos), n-2)
+			t := pos - float64(seg)
+			p0, p1, p2, p3 := &pts[max(seg-1, 0)], &pts[seg], &pts[seg+1], &pts[min(seg+2, n-1)]
+			var values [3]float64
+			for j, coord := range in.Coords {
+				values[j] = catmullRom(p0.Values[j], p1.Values[j], p2.Values[j], p3.Values[j], t)
+				if coord.IsAngle {
+					values[j] = math.Mod(math.Mod(values[j], 360)+360, 360)
+				}
+			}
+			alpha := min(max(catmullRom(p0.Alpha, p1.Alpha, p2.Alpha, p3.Alpha, t), 0), 1)
+			if !yield(Make(in, values[0], values[1], values[2], alpha)) {
+				return
+			}
+		}
+	}
+}
+
+// catmullRom evaluates the uniform Catmull-Rom spline segment between p1 and
+// p2 at t in [0, 1].
+func catmullRom(p0, p1, p2, p3, t float64) float64 {
+	return 0.5 * (2*p1 +
+		(p2-p0)*t +
+		(2*p0-5*p1+4*p2-p3)*t*t +
+		(3*p1-p0-3*p2+p3)*t*t*t)
+}
+
+// fillMissing replaces missing components and alpha of colors, which must all
+// be in the same color space, with the value of the nearest preceding color
+// that has the component, or, failing that, of the nearest following one.
+// Components that are missing in all colors are set to zero.
+func fillMissing(colors []Color) {
+	get := func(c *Color, i int) *float64 {
+		if i == 3 {
+			return &c.Alpha
+		}
+		return &c.Values[i]
+	}
+	for i := range 4 {
+		last := math.NaN()
+		for j := range colors {
+			if v := get(&colors[j], i); math.IsNaN(*v) {
+				*v = last
+			} else {
+				last = *v
+			}
+		}
+		last = 0
+		for j := len(colors) - 1; j >= 0; j-- {
+			if v := get(&colors[j], i); math.IsNaN(*v) {
+				*v = last
+			} else {
+				last = *v
+			}
+		}
+	}
+}
+
+// EaseLinear is the identity easing function, resulting in linear
+// interpolation.
+func EaseLinear(t float64) float64 { return t }
+
+// EaseInQuad is a quadratic easing function that starts slow and accelerates.
+func EaseInQuad(t float64) float64 { return t * t }
+
+// EaseInOutCubic is a cubic easing function that starts slow, accelerates, and
+// slows down again towards the end.
+func EaseInOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := -2*t + 2
+	return 1 - u*u*u/2
+}
+
+// HueInterpolation describes which way around the hue circle to interpolate
+// hues in polar color spaces, as specified by the [CSS Color Module Level 4].
+//
+// [CSS Color Module Level 4]: https://www.w3.org/TR/css-color-4/#hue-interpolation
+type HueInterpolation int
+
+const (
+	// ShorterHue takes the shorter path around the hue circle.
+	ShorterHue HueInterpolation = iota
+	// LongerHue takes the longer path around the hue circle.
+	LongerHue
+	// IncreasingHue interpolates towards increasing hue angles.
+	IncreasingHue
+	// DecreasingHue interpolates towards decreasing hue angles.
+	DecreasingHue
+
+	// rawHue interpolates hue angles like any other coordinate.
+	rawHue HueInterpolation = -1
+)
+
+// fixupHues adjusts two hue angles, in degrees, so that linearly interpolating
+// between them takes the path around the hue circle described by mode.
+func fixupHues(h1, h2 float64, mode HueInterpolation) (float64, float64) {
+	if mode == rawHue {
+		return h1, h2
+	}
+	h1 = math.Mod(math.Mod(h1, 360)+360, 360)
+	h2 = math.Mod(math.Mod(h2, 360)+360, 360)
+	d := h2 - h1
+	switch mode {
+	case ShorterHue:
+		if d > 180 {
+			h1 += 360
+		} else if d < -180 {
+			h2 += 360
+		}
+	case LongerHue:
+		if 0 < d && d < 180 {
+			h1 += 360
+		} else if -180 < d && d <= 0 {
+			h2 += 360
+		}
+	case IncreasingHue:
+		if d < 0 {
+			h2 += 360
+		}
+	case DecreasingHue:
+		if d > 0 {
+			h1 += 360
+		}
+	}
+	return h1, h2
+}
+
+// HueDifference returns the signed shortest angular difference from hue a to
+// hue b, in degrees. The result is in (-180, 180], and positive if b lies
+// counterclockwise, that is, at increasing angles, from a. For example, the
+// difference from 350° to 10° is 20°, and from 10° to 350° it is -20°.
+func HueDifference(a, b float64) float64 {
+	d := math.Mod(b-a, 360)
+	if d > 180 {
+		d -= 360
+	} else if d <= -180 {
+		d += 360
+	}
+	return d
+}
+
+// MeanHue returns the circular mean of hues, in degrees in [0, 360). For
+// example, the mean of 350° and 10° is 0°, not 180°. If the hues cancel each
+// other out, such as 0° and 180°, the mean is undefined and MeanHue returns
+// NaN. MeanHue panics if hues is empty.
+func MeanHue(hues []float64) float64 {
+	if len(hues) == 0 {
+		panic("need at least one hue")
+	}
+	var m hueMean
+	for _, h := range hues {
+		m.add(h, 1)
+	}
+	return m.mean()
+}
+
+// hueMean accumulates the weighted circular mean of hues in degrees.
+type hueMean struct {
+	sines, cosines, weight float64
+}
+
+func (m *hueMean) add(h, weight float64) {
+	sin, cos := math.Sincos(h * math.Pi / 180)
+	m.sines += weight * sin
+	m.cosines += weight * cos
+	m.weight += weight
+}
+
+// mean returns the mean of the added hues in [0, 360), or NaN if they cancel
+// each other out.
+func (m *hueMean) mean() float64 {
+	if math.Hypot(m.sines, m.cosines) < 1e-10*m.weight {
+		return math.NaN()
+	}
+	h := math.Atan2(m.sines, m.cosines) * 180 / math.Pi
+	return math.Mod(h+360, 360)
+}
+
+// fixupColorHues applies fixupHues to the angle coordinates of two colors in
+// the same color space.
+func fixupColorHues(c1, c2 *Color, mode HueInterpolation) {
+	for i, coord := range c1.Space.Coords {
+		if coord.IsAngle {
+			c1.Values[i], c2.Values[i] = fixupHues(c1.Values[i], c2.Values[i], mode)
+		}
+	}
+}
+
+// prepareInterpolation converts c1 and c2 to the interpolation space in and
+// handles missing components as described by the CSS Color Module Level 4:
+// missing components are carried forward to analogous components of the
+// interpolation space, which we identify by their names, and a component that
+// is missing in only one color takes the value of the other color.
+func prepareInterpolation(c1, c2 *Color, in *Space) (Color, Color) {
+	c1in := carryMissing(c1, in)
+	c2in := carryMissing(c2, in)
+	for i := range c1in.Values {
+		if math.IsNaN(c1in.Values[i]) {
+			c1in.Values[i] = c2in.Values[i]
+		} else if math.IsNaN(c2in.Values[i]) {
+			c2in.Values[i] = c1in.Values[i]
+		}
+	}
+	if math.IsNaN(c1in.Alpha) {
+		c1in.Alpha = c2in.Alpha
+	} else if math.IsNaN(c2in.Alpha) {
+		c2in.Alpha = c1in.Alpha
+	}
+	return c1in, c2in
+}
+
+// carryMissing converts c to the space in, carrying missing components forward
+// to analogous components of in, which we identify by their names.
+func carryMissing(c *Color, in *Space) Color {
+	cc := c.Convert(in)
+	if c.Space != in {
+		for i, v := range c.Values {
+			if !math.IsNaN(v) {
+				continue
+			}
+			if idx, ok := in.CoordIndex(c.Space.Coords[i].Name); ok {
+				cc.Values[idx] = math.NaN()
+			}
+		}
+	}
+	return cc
+}
+
+// lerpColor linearly interpolates between two colors in the same color space,
+// whose hues have been prepared by fixupColorHues.
+func lerpColor(c1, c2 *Color, t float64, interp interpolation) Color {
+	alpha := lerp(c1.Alpha, c2.Alpha, t)
+	// When the interpolated alpha is zero, the premultiplied coordinates would
+	// all be zero, too, so we fall back to interpolating the plain
+	// coordinates.
+	premultiplied := interp.premultiplied && alpha != 0 && !math.IsNaN(alpha)
+	var values [3]float64
+	for i, coord := range c1.Space.Coords {
+		switch {
+		case coord.IsAngle:
+			values[i] = lerp(c1.Values[i], c2.Values[i], t)
+			if interp.hue != rawHue {
+				values[i] = math.Mod(values[i], 360)
+			}
+		case premultiplied:
+			values[i] = lerp(c1.Values[i]*c1.Alpha, c2.Values[i]*c2.Alpha, t) / alpha
+		default:
+			values[i] = lerp(c1.Values[i], c2.Values[i], t)
+		}
+	}
+	return Make(c1.Space, values[0], values[1], values[2], alpha)
+}
+
+// Mix linearly interpolates between c1 and c2 in the in color space, at
+// position t in [0, 1], and returns the result in the in color space. Like
+// [Step], it interpolates the alpha values, too, and doesn't treat hue angles
+// specially.
+func Mix(c1, c2 *Color, in *Space, t float64) Color {
+	return mix(c1, c2, in, t, interpolation{hue: rawHue})
+}
+
+// MixPremultiplied is like [Mix], but interpolates in premultiplied alpha
+// form, like [StepPremultiplied].
+func MixPremultiplied(c1, c2 *Color, in *Space, t float64) Color {
+	return mix(c1, c2, in, t, interpolation{hue: rawHue, premultiplied: true})
+}
+
+// mix interpolates between c1 and c2 in the in color space, at position t.
+// The ease field of interp is ignored.
+func mix(c1, c2 *Color, in *Space, t float64, interp interpolation) Color {
+	c1in, c2in := prepareInterpolation(c1, c2, in)
+	fixupColorHues(&c1in, &c2in, interp.hue)
+	return lerpColor(&c1in, &c2in, t, interp)
+}
+
+// MixWeighted mixes colors in the in color space, weighting each color by the
+// corresponding entry of weights, and returns the result in the in color
+// space. It generalizes [MixPremultiplied] to more than two colors: the
+// weights are normalized to sum to 1, coordinates are interpolated in
+// premultiplied alpha form, and angle coordinates, such as hue, use the
+// weighted circular mean, which is missing if the hues cancel each other out,
+// like for [MeanHue]. Missing components don't contribute to the result,
+// and a component that is missing in all colors is missing in the result.
+//
+// MixWeighted panics if colors is empty, if the numbers of colors and weights
+// differ, or if any weight is negative or all weights are zero.
+func MixWeighted(colors []Color, weights []float64, in *Space) Color {
+	if len(colors) == 0 {
+		panic("need at least one color")
+	}
+	if len(colors) != len(weights) {
+		panic("colors and weights have different lengths")
+	}
+	var total float64
+	for _, w := range weights {
+		if !(w >= 0) {
+			panic("weights must be non-negative")
+		}
+		total += w
+	}
+	if !(total > 0) {
+		panic("weights must not all be zero")
+	}
+
+	cs := make([]Color, len(colors))
+	for i := range colors {
+		cs[i] = carryMissing(&colors[i], in)
+	}
+
+	// Alpha
+	var alphaSum, alphaWeight float64
+	for i := range cs {
+		if a := cs[i].Alpha; !math.IsNaN(a) {
+			alphaSum += weights[i] * a
+			alphaWeight += weights[i]
+		}
+	}
+	alpha := math.NaN()
+	if alphaWeight > 0 {
+		alpha = alphaSum / alphaWeight
+	}
+
+	var values [3]float64
+	for j, coord := range in.Coords {
+		var sum, weight float64
+		var premulSum, premulWeight float64
+		var hues hueMean
+		for i := range cs {
+			v := cs[i].Values[j]
+			if math.IsNaN(v) {
+				continue
+			}
+			w := weights[i]
+			weight += w
+			if coord.IsAngle {
+				hues.add(v, w)
+				continue
+			}
+			sum += w * v
+			a := cs[i].Alpha
+			if math.IsNaN(a) {
+				a = alpha
+			}
+			premulSum += w * a * v
+			premulWeight += w * a
+		}
+		switch {
+		case weight == 0:
+			values[j] = math.NaN()
+		case coord.IsAngle:
+			values[j] = hues.mean()
+		case premulWeight > 0:
+			values[j] = premulSum / premulWeight
+		default:
+			// All colors are fully transparent, so there is nothing to
+			// premultiply with.
+			values[j] = sum / weight
+		}
+	}
+	return Color{Values: values, Space: in, Alpha: alpha}
+}
+
+// Average computes the average of colors in the in color space and returns it
+// in the in color space. Coordinates and alpha are averaged arithmetically,
+// except for angle coordinates, such as hue, for which the circular mean is
+// used, as computed by [MeanHue]. For example, the average of the hues 350° and
+// 10° is 0°, not 180°. If the hues cancel each other out, the average's hue is
+// missing.
+func Average(colors []Color, in *Space) Color {
+	if len(colors) == 0 {
+		panic("need at least one color")
+	}
+	var sums [3]float64
+	var hues [3]hueMean
+	var alpha float64
+	for i := range colors {
+		c := colors[i].Convert(in)
+		for j, coord := range in.Coords {
+			if coord.IsAngle {
+				hues[j].add(c.Values[j], 1)
+			} else {
+				sums[j] += c.Values[j]
+			}
+		}
+		alpha += c.Alpha
+	}
+	n := float64(len(colors))
+	var values [3]float64
+	for i, coord := range in.Coords {
+		if coord.IsAngle {
+			values[i] = hues[i].mean()
+		} else {
+			values[i] = sums[i] / n
+		}
+	}
+	return Make(in, values[0], values[1], values[2], alpha/n)
+}
+
 // Chromaticity describes a color's chromaticity in the CIE 1931 xy color space.
 type Chromaticity struct {
 	X float64
@@ -218,6 +771,18 @@ func (chr *Chromaticity) XYZ() [3]float64 {
 	}
 }
 
+// String returns the chromaticity in the form xy(0.3127, 0.3290), with four
+// decimal places.
+func (chr Chromaticity) String() string {
+	return fmt.Sprintf("xy(%.4f, %.4f)", chr.X, chr.Y)
+}
+
+// ApproxEqual reports whether the x and y coordinates of chr and other differ
+// by at most tol each.
+func (chr Chromaticity) ApproxEqual(other Chromaticity, tol float64) bool {
+	return math.Abs(chr.X-other.X) <= tol && math.Abs(chr.Y-other.Y) <= tol
+}
+
 // Color represents a color with 3 coordinates in some color space. The meaning
 // of the values depends on the color space.
 //
@@ -230,13 +795,27 @@ func (chr *Chromaticity) XYZ() [3]float64 {
 // or coverage. The alpha value doesn't affect operations such as color space
 // conversions, gamut mapping, or distance metrics and will simply be preserved.
 // [Step], however, will interpolate between the start and end alpha values.
+//
+// A value of NaN denotes a missing component, which corresponds to the 'none'
+// keyword in CSS. [Parse] produces such values, and [Color.String] and
+// [Color.CSS] serialize them as 'none'.
 type Color struct {
 	Values [3]float64
 	Space  *Space
 	Alpha  float64
 }
 
+// String returns c in the CSS color() notation, using 6 decimal places for all
+// values. Spaces that CSS doesn't define use a dashed identifier, such as
+// color(--oklch ...).
 func (c Color) String() string {
+	return c.FormatPrec(6)
+}
+
+// FormatPrec is like [Color.String], but uses prec decimal places. A negative
+// prec uses the smallest number of digits necessary to represent each value
+// exactly, which omits trailing zeros.
+func (c Color) FormatPrec(prec int) string {
 	var isCSS bool
 	switch c.Space.ID {
 	case "srgb", "srgb-linear", "display-p3", "a98-rgb", "prophoto-rgb",
@@ -249,40 +828,425 @@ func (c Color) String() string {
 		id = "--" + id
 	}
 
+	f := func(v float64) string {
+		if math.IsNaN(v) {
+			return "none"
+		}
+		return strconv.FormatFloat(v, 'f', prec, 64)
+	}
 	if c.Alpha != 1 {
-		return fmt.Sprintf("color(%s %f %f %f / %f)",
-			id, c.Values[0], c.Values[1], c.Values[2], c.Alpha)
+		return fmt.Sprintf("color(%s %s %s %s / %s)",
+			id, f(c.Values[0]), f(c.Values[1]), f(c.Values[2]), f(c.Alpha))
 	} else {
-		return fmt.Sprintf("color(%s %f %f %f)",
-			id, c.Values[0], c.Values[1], c.Values[2])
+		return fmt.Sprintf("color(%s %s %s %s)",
+			id, f(c.Values[0]), f(c.Values[1]), f(c.Values[2]))
 	}
 }
 
+// Format implements [fmt.Formatter]. The verbs %v, %s, and %q format c like
+// [Color.String], unquoted or quoted, with the precision, if specified,
+// controlling the number of decimal places. For example, %.2v uses two decimal
+// places. Width and flags apply to the resulting string as they do for
+// strings, and %#v prints c in Go syntax. %x and %X format the string returned
+// by [Color.String] in hexadecimal.
+func (c Color) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's', 'q':
+		if verb == 'v' && f.Flag('#') {
+			space := "nil"
+			if c.Space != nil {
+				space = fmt.Sprintf("%p", c.Space)
+			}
+			fmt.Fprintf(f, "color.Color{Values:%#v, Space:(*color.Space)(%s), Alpha:%#v}",
+				c.Values, space, c.Alpha)
+			return
+		}
+		prec, ok := f.Precision()
+		if !ok {
+			prec = 6
+		}
+		// The precision has been used for the values and mustn't truncate the
+		// string.
+		directive := "%"
+		for _, flag := range "+-# 0" {
+			if f.Flag(int(flag)) {
+				directive += string(flag)
+			}
+		}
+		if width, ok := f.Width(); ok {
+			directive += strconv.Itoa(width)
+		}
+		fmt.Fprintf(f, directive+string(verb), c.FormatPrec(prec))
+	case 'x', 'X':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), c.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(color.Color=%s)", verb, c.String())
+	}
+}
+
+// Hex returns the CSS hexadecimal notation of c, in the form #rrggbb, or
+// #rrggbbaa if c isn't fully opaque. The color is converted to sRGB and
+// out-of-gamut colors are clipped to the sRGB gamut; see [GamutMapCSS] for a
+// more accurate way of gamut mapping colors.
+func (c Color) Hex() string {
+	r, g, b, a := c.RGBA255()
+	if a != 255 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, a)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// CSS returns the canonical CSS serialization of c, which can be parsed by
+// [Parse] as well as by browsers.
+//
+// sRGB colors are serialized using the legacy rgb() and rgba() functions, with
+// integer values in [0, 255], unless they have missing components, which only
+// the modern rgb() syntax supports. Like for [Color.Hex], out-of-gamut values
+// are clipped. Lab, LCh, Oklab, and Oklch colors use the lab(), lch(), oklab(),
+// and oklch() functions, and other spaces known to CSS use the color()
+// function. Colors in spaces that CSS doesn't know about are converted to XYZ
+// D65 first. Alpha is omitted when it is 1. Values are rounded to at most 6
+// decimal places, with trailing zeros removed.
+func (c Color) CSS() string {
+	var alpha string
+	if c.Alpha != 1 {
+		alpha = cssNumber(c.Alpha)
+	}
+
+	if c.Space == SRGB {
+		if slices.ContainsFunc(c.Values[:], math.IsNaN) || math.IsNaN(c.Alpha) {
+			// The legacy syntax doesn't support missing components.
+			var vals [3]string
+			for i, v := range c.Values {
+				if math.IsNaN(v) {
+					vals[i] = "none"
+				} else {
+					vals[i] = strconv.Itoa(int(to8bit(v)))
+				}
+			}
+			if alpha != "" {
+				return fmt.Sprintf("rgb(%s %s %s / %s)", vals[0], vals[1], vals[2], alpha)
+			}
+			return fmt.Sprintf("rgb(%s %s %s)", vals[0], vals[1], vals[2])
+		}
+		r, g, b, _ := c.RGBA255()
+		if alpha != "" {
+			return fmt.Sprintf("rgba(%d, %d, %d, %s)", r, g, b, alpha)
+		}
+		return fmt.Sprintf("rgb(%d, %d, %d)", r, g, b)
+	}
+
+	var fn string
+	switch c.Space.ID {
+	case "lab", "lch", "oklab", "oklch":
+		fn = c.Space.ID + "("
+	case "srgb-linear", "display-p3", "a98-rgb", "prophoto-rgb", "rec2020",
+		"xyz-d50", "xyz-d65":
+		fn = "color(" + c.Space.ID + " "
+	default:
+		c.ConvertInPlace(XYZ_D65)
+		fn = "color(xyz-d65 "
+	}
+	vals := cssNumber(c.Values[0]) + " " + cssNumber(c.Values[1]) + " " + cssNumber(c.Values[2])
+	if alpha != "" {
+		return fn + vals + " / " + alpha + ")"
+	}
+	return fn + vals + ")"
+}
+
+// cssNumber formats v with at most 6 decimal places and no trailing zeros. NaN
+// is formatted as 'none'.
+func cssNumber(v float64) string {
+	if math.IsNaN(v) {
+		return "none"
+	}
+	s := strconv.FormatFloat(v, 'f', 6, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
 // Convert converts c from its current color space to a different color space.
 // It does not apply any gamut mapping.
+//
+// Missing components, represented as NaN, are treated as zero, as specified by
+// CSS, so that they don't affect unrelated components of the result. Converting
+// to c's own space keeps them as is. See [Space.Convert] for how infinite values
+// are handled.
 func (c *Color) Convert(space *Space) Color {
 	if c.Space == space {
 		return *c
 	}
 
 	return Color{
-		Values: c.Space.Convert(space, c.Values),
+		Values: c.Space.Convert(space, resolveMissing(c.Values)),
 		Space:  space,
 		Alpha:  c.Alpha,
 	}
 }
 
-// InGamut reports whether c's values are in gamut of its color space.
+// resolveMissing returns values with missing components replaced by zero.
+func resolveMissing(values [3]float64) [3]float64 {
+	for i, v := range values {
+		if math.IsNaN(v) {
+			values[i] = 0
+		}
+	}
+	return values
+}
+
+// ConvertInPlace converts c from its current color space to a different color
+// space, updating c's values and space. It is equivalent to
+// *c = c.Convert(space), but the intermediate results of the conversion are
+// stored in c.Values directly, which avoids an allocation per conversion when c
+// already lives on the heap, such as when it is an element of a slice. Any
+// other copies of c are unaffected.
+func (c *Color) ConvertInPlace(space *Space) {
+	if c.Space == space {
+		return
+	}
+	c.Values = resolveMissing(c.Values)
+	c.Space.convertInPlace(space, &c.Values)
+	c.Space = space
+}
+
+// WithLightness returns c with its lightness set to l, as measured in space,
+// which must have a coordinate named "Lightness", such as [Oklch] or [LCh].
+// The returned color is in c's color space.
+func (c *Color) WithLightness(l float64, space *Space) Color {
+	return c.withCoord("Lightness", l, space)
+}
+
+// WithChroma returns c with its chroma set to chroma, as measured in space,
+// which must have a coordinate named "Chroma", such as [Oklch] or [LCh]. The
+// returned color is in c's color space.
+func (c *Color) WithChroma(chroma float64, space *Space) Color {
+	return c.withCoord("Chroma", chroma, space)
+}
+
+// WithAlpha returns c with its alpha set to a. Like in [Make], the alpha is
+// clamped to [0, 1].
+func (c Color) WithAlpha(a float64) Color {
+	return Make(c.Space, c.Values[0], c.Values[1], c.Values[2], a)
+}
+
+// Opaque returns c with an alpha of 1.
+func (c Color) Opaque() Color {
+	c.Alpha = 1
+	return c
+}
+
+// Lighten returns c with amount added to its lightness in [Oklch], where
+// lightness ranges from 0 to 1. The resulting lightness is clamped to that
+// range. The returned color is in c's color space.
+func (c Color) Lighten(amount float64) Color {
+	return c.adjustOklch(func(lch *[3]float64) {
+		lch[0] = max(0, min(1, lch[0]+amount))
+	})
+}
+
+// Darken is like [Color.Lighten], but subtracts amount from the lightness.
+func (c Color) Darken(amount float64) Color {
+	return c.Lighten(-amount)
+}
+
+// Saturate returns c with amount added to its chroma in [Oklch]. The resulting
+// chroma is clamped to be non-negative. The returned color is in c's color
+// space and may be out of gamut.
+func (c Color) Saturate(amount float64) Color {
+	return c.adjustOklch(func(lch *[3]float64) {
+		lch[1] = max(0, lch[1]+amount)
+	})
+}
+
+// Desaturate is like [Color.Saturate], but subtracts amount from the chroma.
+func (c Color) Desaturate(amount float64) Color {
+	return c.Saturate(-amount)
+}
+
+// RotateHue returns c with its hue in [Oklch] rotated by deg degrees. The
+// returned color is in c's color space.
+func (c Color) RotateHue(deg float64) Color {
+	return c.adjustOklch(func(lch *[3]float64) {
+		lch[2] = math.Mod(math.Mod(lch[2]+deg, 360)+360, 360)
+	})
+}
+
+// Exposure returns c with its exposure adjusted by the given number of stops,
+// that is, with its linear light intensities multiplied by 2^stops. Positive
+// values brighten, negative values darken. The returned color is in c's color
+// space and may lie outside its gamut.
+func (c Color) Exposure(stops float64) Color {
+	xyz := c.Convert(XYZ_D65)
+	k := math.Exp2(stops)
+	for i := range xyz.Values {
+		xyz.Values[i] *= k
+	}
+	return xyz.Convert(c.Space)
+}
+
+// Gamma returns c with a power curve applied to its gamma-encoded [SRGB]
+// channels, raising each channel to the power of 1/g. Values of g greater than
+// 1 brighten the midtones, values less than 1 darken them, and black and white
+// are unaffected. The returned color is in c's color space.
+func (c Color) Gamma(g float64) Color {
+	rgb := c.Convert(SRGB)
+	for i, v := range rgb.Values {
+		rgb.Values[i] = math.Copysign(math.Pow(math.Abs(v), 1/g), v)
+	}
+	return rgb.Convert(c.Space)
+}
+
+func (c *Color) adjustOklch(fn func(lch *[3]float64)) Color {
+	lch := c.Convert(Oklch)
+	fn(&lch.Values)
+	return lch.Convert(c.Space)
+}
+
+func (c *Color) withCoord(name string, v float64, space *Space) Color {
+	idx, ok := space.CoordIndex(name)
+	if !ok {
+		panic(fmt.Sprintf("color space %s has no coordinate %q", space.Name, name))
+	}
+	cc := c.Convert(space)
+	cc.Values[idx] = v
+	return cc.Convert(c.Space)
+}
+
+// Get returns the value of the coordinate with the given name in c's color
+// space. ok is false if the space has no such coordinate.
+func (c *Color) Get(name string) (v float64, ok bool) {
+	idx, ok := c.Space.CoordIndex(name)
+	if !ok {
+		return 0, false
+	}
+	return c.Values[idx], true
+}
+
+// Set sets the value of the coordinate with the given name in c's color space.
+// It panics if the space has no such coordinate.
+func (c *Color) Set(name string, v float64) {
+	idx, ok := c.Space.CoordIndex(name)
+	if !ok {
+		panic(fmt.Sprintf("color space %s has no coordinate %q", c.Space.Name, name))
+	}
+	c.Values[idx] = v
+}
+
+// IsAchromatic reports whether c is effectively a shade of gray, that is,
+// whether it has no meaningful hue. This uses the same threshold as the
+// conversion to [Oklch], which assigns such colors a hue of 0.
+func (c *Color) IsAchromatic() bool {
+	lab := c.Convert(Oklab)
+	return isAchromatic(lab.Values[1], lab.Values[2], oklabAchromaticϵ)
+}
+
+// HWBComponents returns the hue, whiteness, and blackness of c, as in CSS's
+// hwb() notation, computed from c's [SRGB] coordinates. Whiteness and
+// blackness range from 0 to 1 for colors in the sRGB gamut. The hue is in
+// degrees, and is 0 for grays.
+func (c *Color) HWBComponents() (h, w, b float64) {
+	rgb := c.Convert(SRGB)
+	lo := min(rgb.Values[0], rgb.Values[1], rgb.Values[2])
+	hi := max(rgb.Values[0], rgb.Values[1], rgb.Values[2])
+	return rgbHue(&rgb.Values, lo, hi), lo, 1 - hi
+}
+
+// HSLSaturation returns the saturation of c, as in CSS's hsl() notation,
+// computed from c's [SRGB] coordinates. It ranges from 0 to 1 for colors in
+// the sRGB gamut.
+func (c *Color) HSLSaturation() float64 {
+	rgb := c.Convert(SRGB)
+	lo := min(rgb.Values[0], rgb.Values[1], rgb.Values[2])
+	hi := max(rgb.Values[0], rgb.Values[1], rgb.Values[2])
+	l := (lo + hi) / 2
+	if l == 0 || l == 1 {
+		return 0
+	}
+	return (hi - l) / min(l, 1-l)
+}
+
+// HSVValue returns the value, or brightness, of c in the HSV model, computed
+// from c's [SRGB] coordinates. It is the largest of the three coordinates.
+func (c *Color) HSVValue() float64 {
+	rgb := c.Convert(SRGB)
+	return max(rgb.Values[0], rgb.Values[1], rgb.Values[2])
+}
+
+// ChromaLCh returns the chroma of c in [LCh].
+func (c *Color) ChromaLCh() float64 {
+	return c.Convert(LCh).Values[1]
+}
+
+// ChromaOklch returns the chroma of c in [Oklch].
+func (c *Color) ChromaOklch() float64 {
+	return c.Convert(Oklch).Values[1]
+}
+
+// rgbHue returns the hue of an RGB color, as used by HSL, HSV, and HWB, given
+// its smallest and largest coordinates.
+func rgbHue(rgb *[3]float64, lo, hi float64) float64 {
+	d := hi - lo
+	if d == 0 {
+		return 0
+	}
+	r, g, b := rgb[0], rgb[1], rgb[2]
+	var h float64
+	switch hi {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60
+}
+
+// InGamut reports whether c's values are in gamut of its color space. Missing
+// components are treated as zero.
 func (c *Color) InGamut() bool {
-	return c.Space.InGamut(c.Values)
+	return c.Space.InGamut(resolveMissing(c.Values))
 }
 
-// InGamutOf reports whether c, when converted to space, is in gamut.
+// InGamutOf reports whether c, when converted to space, is in gamut. Missing
+// components are treated as zero.
 func (c *Color) InGamutOf(space *Space) bool {
 	cc := c.Convert(space)
 	return cc.InGamut()
 }
 
+// ApproxEqual reports whether c and other, when converted to space, differ by at
+// most tolerance in each coordinate and in alpha. Differences in angle
+// coordinates take wraparound into account, so that hues of 359° and 1° differ
+// by 2°.
+func (c *Color) ApproxEqual(other *Color, space *Space, tolerance float64) bool {
+	if math.Abs(c.Alpha-other.Alpha) > tolerance {
+		return false
+	}
+	c1 := c.Convert(space)
+	c2 := other.Convert(space)
+	for i, coord := range space.Coords {
+		d := math.Abs(c1.Values[i] - c2.Values[i])
+		if coord.IsAngle {
+			d = math.Mod(d, 360)
+			d = min(d, 360-d)
+		}
+		if !(d <= tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
 // GamutMapCSS uses the [CSS gamut mapping algorithm] to map individual colors
 // to a destination color space. It implements a relative colorimetric intent.
 // That is, colors that are already inside the target gamut are unchanged. This
@@ -290,20 +1254,155 @@ func (c *Color) InGamutOf(space *Space) bool {
 //
 // For some limitations of this algorithm, see [1] and [2].
 //
+// Missing components are treated as zero. Colors with infinite coordinates
+// can't be meaningfully mapped and are only converted to the destination space.
+//
 // [CSS gamut mapping algorithm]: https://www.w3.org/TR/css-color-4/#css-gamut-mapping
 // [1]: https://github.com/w3c/csswg-drafts/issues/7071
 // [2]: https://github.com/w3c/csswg-drafts/issues/9449
 func GamutMapCSS(c *Color, to *Space) Color {
+	return GamutMapCSSOpts(c, to, GamutMapOptions{})
+}
+
+// GamutMapCSSReport is like [GamutMapCSS], but also reports whether mapping
+// changed the color, that is, whether the result differs from c converted to
+// the destination space by more than the tolerance used by [Space.InGamut].
+func GamutMapCSSReport(c *Color, to *Space) (Color, bool) {
+	mapped := GamutMapCSS(c, to)
+	converted := c.Convert(to)
+	return mapped, !mapped.ApproxEqual(&converted, to, 0.000075)
+}
+
+// GamutMapOptions configures [GamutMapCSSOpts]. The zero value of each field
+// selects the value used by the CSS specification.
+type GamutMapOptions struct {
+	// DeltaE is the color difference metric used to compare the clipped and
+	// unclipped colors. It defaults to [DeltaEOK].
+	DeltaE DeltaEFunc
+	// JND is the just noticeable difference, the largest color difference
+	// between the clipped and unclipped color that is considered acceptable.
+	// Its scale depends on DeltaE. It defaults to 0.02, which is suitable for
+	// DeltaEOK and [DeltaEOK2].
+	JND float64
+	// Epsilon is the precision with which the chroma is searched for. It
+	// defaults to 0.0001.
+	Epsilon float64
+}
+
+// GamutMapCSSOpts is like [GamutMapCSS], but allows configuring the
+// algorithm's parameters.
+func GamutMapCSSOpts(c *Color, to *Space, opts GamutMapOptions) Color {
 	// 1. if destination has no gamut limits (XYZ-D65, XYZ-D50, Lab, LCH,
 	// Oklab, Oklch) convert origin to destination and return it as the
 	// gamut mapped color
-	if to.Coords[0].Range == infty &&
-		to.Coords[1].Range == infty &&
-		to.Coords[2].Range == infty {
+	if isUnbounded(to) {
 		return c.Convert(to)
 	}
+	return gamutMapCSS(c, to, opts.withDefaults())
+}
+
+func (opts GamutMapOptions) withDefaults() GamutMapOptions {
+	if opts.DeltaE == nil {
+		opts.DeltaE = DeltaEOK
+	}
+	if opts.JND == 0 {
+		// The just noticeable difference between two colors in Oklch
+		opts.JND = 0.02
+	}
+	if opts.Epsilon == 0 {
+		opts.Epsilon = 0.0001
+	}
+	return opts
+}
+
+// GamutMapCSSBatch applies [GamutMapCSS] to each color in colors, storing the
+// results in dst, which must be at least as long as colors. dst and colors may
+// be the same slice, in which case colors are mapped in place. The conversions
+// between Oklch and the source and destination spaces are computed once and
+// reused for all colors, which makes this cheaper than calling [GamutMapCSS]
+// for each color, especially when the colors share a space.
+//
+// GamutMapCSSBatch doesn't modify any shared state and may be called
+// concurrently on disjoint slices, for example to process different parts of an
+// image in parallel.
+func GamutMapCSSBatch(colors []Color, to *Space, dst []Color) {
+	if len(dst) < len(colors) {
+		panic("destination is too small")
+	}
+	if isUnbounded(to) {
+		for i := range colors {
+			dst[i] = colors[i].Convert(to)
+		}
+		return
+	}
+	m := newGamutMapper(to, GamutMapOptions{}.withDefaults())
+	for i := range colors {
+		dst[i] = m.mapColor(&colors[i])
+	}
+}
+
+// isUnbounded reports whether none of the color space's coordinates have
+// gamut limits.
+func isUnbounded(cs *Space) bool {
+	return cs.Coords[0].Range == infty &&
+		cs.Coords[1].Range == infty &&
+		cs.Coords[2].Range == infty
+}
+
+// gamutMapCSS implements GamutMapCSSOpts for destination spaces with gamut
+// limits. All options must have been set.
+func gamutMapCSS(c *Color, to *Space, opts GamutMapOptions) Color {
+	return newGamutMapper(to, opts).mapColor(c)
+}
+
+// gamutMapper holds the per-destination state of the CSS gamut mapping
+// algorithm, so that it can be reused for many colors.
+type gamutMapper struct {
+	to   *Space
+	opts GamutMapOptions
+	// fromOklch converts from Oklch to the destination space. toOklch converts
+	// from the space of the most recently mapped color to Oklch.
+	fromOklch *Converter
+	toOklch   *Converter
+}
+
+func newGamutMapper(to *Space, opts GamutMapOptions) *gamutMapper {
+	return &gamutMapper{
+		to:        to,
+		opts:      opts,
+		fromOklch: NewConverter(Oklch, to),
+	}
+}
+
+// clip converts the Oklch color c to the destination space and clamps each
+// coordinate to its range, like GamutClip.
+func (m *gamutMapper) clip(c *Color) Color {
+	values := m.fromOklch.Convert(c.Values)
+	for i, coord := range m.to.Coords {
+		if !coord.IsAngle {
+			values[i] = min(max(values[i], coord.Range[0]), coord.Range[1])
+		}
+	}
+	return Color{Values: values, Space: m.to, Alpha: c.Alpha}
+}
 
-	cOklch := c.Convert(Oklch)
+// inGamut reports whether the Oklch color c is in the destination gamut.
+func (m *gamutMapper) inGamut(c *Color) bool {
+	return m.to.InGamut(m.fromOklch.Convert(c.Values))
+}
+
+func (m *gamutMapper) mapColor(c *Color) Color {
+	to := m.to
+	c = &Color{Values: resolveMissing(c.Values), Space: c.Space, Alpha: c.Alpha}
+	if !isFinite(c.Values) {
+		// Don't let infinities reach the binary search below, which would never
+		// terminate for an infinite chroma.
+		return c.Convert(to)
+	}
+	if m.toOklch == nil || m.toOklch.From() != c.Space {
+		m.toOklch = NewConverter(c.Space, Oklch)
+	}
+	cOklch := Color{Values: m.toOklch.Convert(c.Values), Space: Oklch, Alpha: c.Alpha}
 	if cOklch.Values[0] >= 1 {
 		out := Make(Oklab, 1, 0, 0, c.Alpha)
 		return out.Convert(to)
@@ -313,34 +1412,16 @@ func GamutMapCSS(c *Color, to *Space) Color {
 		return out.Convert(to)
 	}
 
-	if out := cOklch.Convert(to); out.InGamut() {
-		return out
+	if values := m.fromOklch.Convert(cOklch.Values); to.InGamut(values) {
+		return Color{Values: values, Space: to, Alpha: c.Alpha}
 	}
 
-	// The just noticeable difference between two colors in Oklch
-	const jnd = 0.02
-	const ϵ = 0.0001
-
-	clip := func(cc *Color) Color {
-		clamp := func(f, low, high float64) float64 {
-			if f < low {
-				return low
-			}
-			if f > high {
-				return high
-			}
-			return f
-		}
-		ccc := cc.Convert(to)
-		ccc.Values[0] = clamp(ccc.Values[0], ccc.Space.Coords[0].Range[0], ccc.Space.Coords[0].Range[1])
-		ccc.Values[1] = clamp(ccc.Values[1], ccc.Space.Coords[1].Range[0], ccc.Space.Coords[1].Range[1])
-		ccc.Values[2] = clamp(ccc.Values[2], ccc.Space.Coords[2].Range[0], ccc.Space.Coords[2].Range[1])
-		return ccc
-	}
+	jnd := m.opts.JND
+	ϵ := m.opts.Epsilon
 
 	current := cOklch
-	clipped := clip(&current)
-	e := DeltaEOK(&clipped, &current)
+	clipped := m.clip(&current)
+	e := m.opts.DeltaE(&clipped, &current)
 	if e < jnd {
 		return clipped
 	}
@@ -350,12 +1431,13 @@ func GamutMapCSS(c *Color, to *Space) Color {
 	for max-min > ϵ {
 		chroma := (min + max) / 2
 		current.Values[1] = chroma
-		if minInGamut && current.InGamutOf(to) {
+		inGamut := m.inGamut(&current)
+		if minInGamut && inGamut {
 			min = chroma
 			continue
-		} else if !current.InGamutOf(to) {
-			clipped = clip(&current)
-			e = DeltaEOK(&clipped, &current)
+		} else if !inGamut {
+			clipped = m.clip(&current)
+			e = m.opts.DeltaE(&clipped, &current)
 			if e < jnd {
 				if jnd-e < ϵ {
 					return clipped
@@ -372,6 +1454,21 @@ func GamutMapCSS(c *Color, to *Space) Color {
 	return clipped
 }
 
+// GamutClip converts c to the destination color space and clamps each
+// coordinate to its range, without regard for the perceptual impact. Angle
+// coordinates are left untouched. This is much faster than [GamutMapCSS] but
+// can cause noticeable shifts in hue and lightness.
+func GamutClip(c *Color, to *Space) Color {
+	cc := c.Convert(to)
+	for i, coord := range to.Coords {
+		if coord.IsAngle {
+			continue
+		}
+		cc.Values[i] = min(max(cc.Values[i], coord.Range[0]), coord.Range[1])
+	}
+	return cc
+}
+
 // Coordinate is metadata describing a coordinate of a color space.
 type Coordinate struct {
 	// Name is the human readable name of the coordinate.
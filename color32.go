@@ -0,0 +1,71 @@
+package color
+
+// Color32 is like [Color], but stores its values and alpha as float32. It uses
+// half the memory of Color, which is useful for large buffers of colors, such
+// as images. Computations are carried out in float64 and only the results are
+// stored in float32.
+type Color32 struct {
+	Values [3]float32
+	Space  *Space
+	Alpha  float32
+}
+
+// To32 returns c with its values and alpha rounded to float32.
+func (c Color) To32() Color32 {
+	return Color32{
+		Values: [3]float32{float32(c.Values[0]), float32(c.Values[1]), float32(c.Values[2])},
+		Space:  c.Space,
+		Alpha:  float32(c.Alpha),
+	}
+}
+
+// To64 returns c as a [Color].
+func (c Color32) To64() Color {
+	return Color{
+		Values: [3]float64{float64(c.Values[0]), float64(c.Values[1]), float64(c.Values[2])},
+		Space:  c.Space,
+		Alpha:  float64(c.Alpha),
+	}
+}
+
+// Convert converts c from its current color space to a different color space.
+// The conversion is computed in float64. It does not apply any gamut mapping.
+func (c Color32) Convert(space *Space) Color32 {
+	if c.Space == space {
+		return c
+	}
+	c64 := c.To64()
+	c64.ConvertInPlace(space)
+	return c64.To32()
+}
+
+// Float is the set of floating point types that coordinates can be stored as.
+type Float interface {
+	~float32 | ~float64
+}
+
+// ConvertValues converts coordinates, stored as either float32 or float64,
+// from the color space from to the color space to. Like [Space.Convert], it
+// computes in float64 and propagates missing (NaN) components; for float64
+// coordinates, the results are identical.
+func ConvertValues[T Float](from, to *Space, values [3]T) [3]T {
+	v := [3]float64{float64(values[0]), float64(values[1]), float64(values[2])}
+	v = from.Convert(to, v)
+	return [3]T{T(v[0]), T(v[1]), T(v[2])}
+}
+
+// ConvertValuesSlice is like [ConvertValues], but converts a slice of
+// coordinates, storing the results in dst, which must be at least as long as
+// values. dst and values may be the same slice. It reuses a single [Converter]
+// for all coordinates, and its results are identical to those of
+// [Converter.Convert].
+func ConvertValuesSlice[T Float](from, to *Space, values, dst [][3]T) {
+	if len(dst) < len(values) {
+		panic("destination is too small")
+	}
+	cv := NewConverter(from, to)
+	for i, in := range values {
+		v := cv.Convert([3]float64{float64(in[0]), float64(in[1]), float64(in[2])})
+		dst[i] = [3]T{T(v[0]), T(v[1]), T(v[2])}
+	}
+}
@@ -0,0 +1,130 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColor32(t *testing.T) {
+	inputs := [][3]float64{
+		{0, 0, 0},
+		{1, 1, 1},
+		{0.2, 0.5, 0.8},
+		{0.9, 0.1, 0.3},
+	}
+	// Converting from float32 inputs adds error on top of the final rounding,
+	// so allow for a few ulps.
+	const ϵ = 8 * 0x1p-23
+
+	for _, in := range inputs {
+		c := Make(SRGB, in[0], in[1], in[2], 0.5)
+		c32 := c.To32()
+		got := c32.To64()
+		for i := range got.Values {
+			if math.Abs(got.Values[i]-c.Values[i]) > ϵ {
+				t.Errorf("round trip of %v: got %v", c, got)
+				break
+			}
+		}
+
+		for _, space := range []*Space{LinearSRGB, DisplayP3, Oklab, Oklch, Lab, XYZ_D50} {
+			want := c.Convert(space)
+			got := c32.Convert(space).To64()
+			if got.Space != space {
+				t.Errorf("got space %s, want %s", got.Space.ID, space.ID)
+			}
+			if got.Alpha != 0.5 {
+				t.Errorf("got alpha %g, want 0.5", got.Alpha)
+			}
+			for i := range got.Values {
+				scale := max(1, math.Abs(want.Values[i]))
+				if math.Abs(got.Values[i]-want.Values[i]) > ϵ*scale {
+					t.Errorf("converting %v to %s: got %v, want %v", c, space.ID, got.Values, want.Values)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestConvertValues(t *testing.T) {
+	inputs := [][3]float64{
+		{0, 0, 0},
+		{1, 1, 1},
+		{0.2, 0.5, 0.8},
+		{0.9, 0.1, 0.3},
+	}
+	const ϵ = 8 * 0x1p-23
+
+	for _, to := range []*Space{LinearSRGB, DisplayP3, Oklab, Oklch, Lab, XYZ_D50} {
+		var in32 [][3]float32
+		for _, in := range inputs {
+			want := SRGB.Convert(to, in)
+			if got := ConvertValues(SRGB, to, in); got != want {
+				t.Errorf("float64 %v -> %s: got %v, want %v", in, to.ID, got, want)
+			}
+
+			v32 := [3]float32{float32(in[0]), float32(in[1]), float32(in[2])}
+			in32 = append(in32, v32)
+			got := ConvertValues(SRGB, to, v32)
+			for i := range got {
+				scale := max(1, math.Abs(want[i]))
+				if math.Abs(float64(got[i])-want[i]) > ϵ*scale {
+					t.Errorf("float32 %v -> %s: got %v, want %v", in, to.ID, got, want)
+					break
+				}
+			}
+		}
+
+		dst := make([][3]float32, len(in32))
+		ConvertValuesSlice(SRGB, to, in32, dst)
+		cv := NewConverter(SRGB, to)
+		for i, v := range in32 {
+			w := cv.Convert([3]float64{float64(v[0]), float64(v[1]), float64(v[2])})
+			if want := [3]float32{float32(w[0]), float32(w[1]), float32(w[2])}; dst[i] != want {
+				t.Errorf("%v -> %s: got %v, want %v", v, to.ID, dst[i], want)
+			}
+		}
+	}
+
+	type myFloat float32
+	if got := ConvertValues(LinearSRGB, SRGB, [3]myFloat{0, 1, 0.5}); got[0] != 0 || got[1] != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func BenchmarkConvertValuesSlice(b *testing.B) {
+	const n = 1920 * 1080
+	b.Run("float32", func(b *testing.B) {
+		buf := make([][3]float32, n)
+		for i := range buf {
+			buf[i] = [3]float32{float32(i%256) / 255, 0.5, 0.25}
+		}
+		b.SetBytes(int64(n * 12))
+		for range b.N {
+			ConvertValuesSlice(SRGB, DisplayP3, buf, buf)
+		}
+	})
+	b.Run("float64", func(b *testing.B) {
+		buf := make([][3]float64, n)
+		for i := range buf {
+			buf[i] = [3]float64{float64(i%256) / 255, 0.5, 0.25}
+		}
+		b.SetBytes(int64(n * 24))
+		for range b.N {
+			ConvertValuesSlice(SRGB, DisplayP3, buf, buf)
+		}
+	})
+	b.Run("Color32", func(b *testing.B) {
+		buf := make([]Color32, n)
+		for i := range buf {
+			buf[i] = Make(SRGB, float64(i%256)/255, 0.5, 0.25, 1).To32()
+		}
+		for range b.N {
+			for i := range buf {
+				buf[i] = buf[i].Convert(DisplayP3)
+				buf[i].Space = SRGB
+			}
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package color
 
 import (
+	"fmt"
+	"math"
 	"slices"
 	"testing"
 )
@@ -42,4 +44,900 @@ func TestStep(t *testing.T) {
 		}
 	})
 
+	t.Run("inclusive", func(t *testing.T) {
+		// The endpoints are exact in the interpolation space, not just after
+		// converting back.
+		c1 := Make(SRGB, 0.8, 0.2, 0.1, 0.5)
+		c2 := Make(DisplayP3, 0.1, 0.3, 0.9, 1)
+		for _, in := range []*Space{Oklch, Lab, LinearSRGB} {
+			for _, num := range []int{2, 3, 10} {
+				got := slices.Collect(Step(&c1, &c2, in, in, num))
+				if want := c1.Convert(in); got[0] != want {
+					t.Errorf("%s, %d steps: got first step %v, want %v", in.Name, num, got[0], want)
+				}
+				if want := c2.Convert(in); got[num-1] != want {
+					t.Errorf("%s, %d steps: got last step %v, want %v", in.Name, num, got[num-1], want)
+				}
+			}
+		}
+	})
+}
+
+func TestLabToXYZ(t *testing.T) {
+	// Reference values for sRGB red from CSS Color 4's sample code.
+	tests := []struct {
+		lab, xyz [3]float64
+	}{
+		{
+			[3]float64{54.29054294696968, 80.80492033462421, 69.89098825896275},
+			[3]float64{0.43606574687426936, 0.22249319175623702, 0.013923904500943465},
+		},
+		{[3]float64{100, 0, 0}, WhitesCSSD50.XYZ()},
+		{[3]float64{0, 0, 0}, [3]float64{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		got := Lab.Convert(XYZ_D50, tt.lab)
+		back := XYZ_D50.Convert(Lab, tt.xyz)
+		for i := range got {
+			if math.Abs(got[i]-tt.xyz[i]) > 1e-5 {
+				t.Errorf("Lab %v -> XYZ: got %v, want %v", tt.lab, got, tt.xyz)
+				break
+			}
+		}
+		for i := range back {
+			if math.Abs(back[i]-tt.lab[i]) > 1e-5 {
+				t.Errorf("XYZ %v -> Lab: got %v, want %v", tt.xyz, back, tt.lab)
+				break
+			}
+		}
+	}
+}
+
+func TestWithLightness(t *testing.T) {
+	c := Make(SRGB, 0.8, 0.3, 0.1, 1)
+	for _, space := range []*Space{LCh, Oklch, Lab} {
+		l := space.Coords[0].RefRange[1] * 0.4
+		got := c.WithLightness(l, space)
+		if got.Space != SRGB {
+			t.Errorf("%s: got space %s, want %s", space.Name, got.Space.Name, SRGB.Name)
+		}
+		if gotL := got.Convert(space).Values[0]; math.Abs(gotL-l) > 1e-9 {
+			t.Errorf("%s: got lightness %g, want %g", space.Name, gotL, l)
+		}
+	}
+}
+
+func TestWithChroma(t *testing.T) {
+	c := Make(SRGB, 0.8, 0.3, 0.1, 1)
+	got := c.WithChroma(20, LCh)
+	gotLCh := got.Convert(LCh)
+	if math.Abs(gotLCh.Values[1]-20) > 1e-9 {
+		t.Errorf("got chroma %g, want 20", gotLCh.Values[1])
+	}
+	wantLCh := c.Convert(LCh)
+	if math.Abs(gotLCh.Values[0]-wantLCh.Values[0]) > 1e-9 || math.Abs(gotLCh.Values[2]-wantLCh.Values[2]) > 1e-9 {
+		t.Errorf("got %v, want lightness and hue of %v", gotLCh, wantLCh)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for color space without chroma")
+		}
+	}()
+	c.WithChroma(20, Lab)
+}
+
+func TestNamedColors(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want string
+	}{
+		{Black, "#000000"},
+		{White, "#ffffff"},
+		{Red, "#ff0000"},
+		{Green, "#00ff00"},
+		{Blue, "#0000ff"},
+		{Cyan, "#00ffff"},
+		{Magenta, "#ff00ff"},
+		{Yellow, "#ffff00"},
+		{Transparent, "#00000000"},
+	}
+	for _, tt := range tests {
+		if tt.c.Space != SRGB {
+			t.Errorf("%s: got space %s, want %s", tt.want, tt.c.Space.Name, SRGB.Name)
+		}
+		if got := tt.c.Hex(); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestHex(t *testing.T) {
+	tests := []struct {
+		in   Color
+		want string
+	}{
+		{Make(SRGB, 1, 0, 0, 1), "#ff0000"},
+		{Make(SRGB, 0.5, 0.5, 0.5, 1), "#808080"},
+		{Make(SRGB, 0, 0, 1, 0.5), "#0000ff80"},
+		{Make(LinearSRGB, 1, 1, 1, 1), "#ffffff"},
+		// Out of gamut colors get clipped.
+		{Make(DisplayP3, 1, 0, 0, 1), "#ff0000"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Hex(); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.in, got, tt.want)
+		}
+		if got, ok := ParseHex(tt.want); ok && got.Hex() != tt.want {
+			t.Errorf("%s didn't round-trip, got %s", tt.want, got.Hex())
+		}
+	}
+}
+
+func TestStepFunc(t *testing.T) {
+	c1 := Make(LinearSRGB, 0, 0, 0, 1)
+	c2 := Make(LinearSRGB, 1, 0, 0, 1)
+
+	tests := []struct {
+		name string
+		ease func(float64) float64
+		mid  float64
+	}{
+		{"linear", EaseLinear, 0.5},
+		{"in-quad", EaseInQuad, 0.25},
+		{"in-out-cubic", EaseInOutCubic, 0.5},
+	}
+	for _, tt := range tests {
+		got := slices.Collect(StepFunc(&c1, &c2, LinearSRGB, LinearSRGB, 5, tt.ease))
+		if got[0] != c1 {
+			t.Errorf("%s: got first step %v, want %v", tt.name, got[0], c1)
+		}
+		if got[len(got)-1] != c2 {
+			t.Errorf("%s: got last step %v, want %v", tt.name, got[len(got)-1], c2)
+		}
+		if g := got[2].Values[0]; g != tt.mid {
+			t.Errorf("%s: got midpoint %g, want %g", tt.name, g, tt.mid)
+		}
+	}
+
+	// The cubic easing starts slower than linear interpolation.
+	if g := EaseInOutCubic(0.25); g != 0.0625 {
+		t.Errorf("got %g, want 0.0625", g)
+	}
+	if g := EaseInOutCubic(0.75); g != 0.9375 {
+		t.Errorf("got %g, want 0.9375", g)
+	}
+}
+
+func TestStepHue(t *testing.T) {
+	c1 := Make(Oklch, 0.7, 0.1, 20, 1)
+	c2 := Make(Oklch, 0.7, 0.1, 340, 1)
+
+	tests := []struct {
+		mode HueInterpolation
+		want []float64
+	}{
+		{ShorterHue, []float64{20, 0, 340}},
+		{LongerHue, []float64{20, 180, 340}},
+		{IncreasingHue, []float64{20, 180, 340}},
+		{DecreasingHue, []float64{20, 0, 340}},
+	}
+	for _, tt := range tests {
+		got := slices.Collect(StepHue(&c1, &c2, Oklch, Oklch, 3, tt.mode))
+		for i, c := range got {
+			if math.Abs(c.Values[2]-tt.want[i]) > 1e-9 {
+				t.Errorf("mode %d, step %d: got hue %g, want %g", tt.mode, i, c.Values[2], tt.want[i])
+			}
+		}
+	}
+
+	// Going from 340° to 20° reverses which modes take which path.
+	got := slices.Collect(StepHue(&c2, &c1, Oklch, Oklch, 5, IncreasingHue))
+	for i, want := range []float64{340, 350, 0, 10, 20} {
+		if math.Abs(got[i].Values[2]-want) > 1e-9 {
+			t.Errorf("step %d: got hue %g, want %g", i, got[i].Values[2], want)
+		}
+	}
+}
+
+func TestMix(t *testing.T) {
+	c1 := Make(SRGB, 1, 0, 0, 1)
+	c2 := Make(SRGB, 0, 0, 1, 0.5)
+	for _, in := range []*Space{LinearSRGB, Oklab} {
+		c1in := c1.Convert(in)
+		c2in := c2.Convert(in)
+		if got := Mix(&c1, &c2, in, 0); got != c1in {
+			t.Errorf("%s: got %v at t=0, want %v", in.Name, got, c1in)
+		}
+		if got := Mix(&c1, &c2, in, 1); got != c2in {
+			t.Errorf("%s: got %v at t=1, want %v", in.Name, got, c2in)
+		}
+		got := Mix(&c1, &c2, in, 0.5)
+		for i := range got.Values {
+			if want := (c1in.Values[i] + c2in.Values[i]) / 2; math.Abs(got.Values[i]-want) > 1e-12 {
+				t.Errorf("%s: got %v at t=0.5, want coordinate %d = %g", in.Name, got, i, want)
+			}
+		}
+		if got.Alpha != 0.75 {
+			t.Errorf("%s: got alpha %g at t=0.5, want 0.75", in.Name, got.Alpha)
+		}
+		steps := slices.Collect(Step(&c1, &c2, in, in, 3))
+		if steps[1] != got {
+			t.Errorf("%s: got %v, want %v to match Step", in.Name, got, steps[1])
+		}
+	}
+}
+
+func TestStepPremultiplied(t *testing.T) {
+	red := Make(SRGB, 1, 0, 0, 1)
+	transparent := Make(SRGB, 0, 0, 1, 0)
+
+	straight := slices.Collect(Step(&red, &transparent, SRGB, SRGB, 3))
+	if want := Make(SRGB, 0.5, 0, 0.5, 0.5); straight[1] != want {
+		t.Errorf("got %v, want %v", straight[1], want)
+	}
+
+	// With premultiplied alpha, the fully transparent color contributes no
+	// hue, so the gradient stays red.
+	premul := slices.Collect(StepPremultiplied(&red, &transparent, SRGB, SRGB, 3))
+	for i, want := range []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 1, 0, 0, 0.5),
+		Make(SRGB, 0, 0, 1, 0),
+	} {
+		if premul[i] != want {
+			t.Errorf("step %d: got %v, want %v", i, premul[i], want)
+		}
+	}
+
+	if got, want := MixPremultiplied(&red, &transparent, SRGB, 0.5), premul[1]; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Interpolating between two transparent colors mustn't divide by zero.
+	transparentRed := Make(SRGB, 1, 0, 0, 0)
+	got := MixPremultiplied(&transparentRed, &transparent, SRGB, 0.5)
+	if want := Make(SRGB, 0.5, 0, 0.5, 0); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	stops := []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0, 1, 0, 1),
+		Make(DisplayP3, 0, 0, 1, 0.5),
+	}
+	got := slices.Collect(Gradient(stops, []float64{0, 0.5, 1}, Oklab, 9))
+	if len(got) != 9 {
+		t.Fatalf("got %d colors, want 9", len(got))
+	}
+	for i, idx := range []int{0, 4, 8} {
+		want := stops[i].Convert(Oklab)
+		if got[idx] != want {
+			t.Errorf("color %d: got %v, want stop %v", idx, got[idx], want)
+		}
+	}
+
+	// With two stops at the ends, Gradient is the same as Step.
+	want := slices.Collect(Step(&stops[0], &stops[2], Oklab, Oklab, 7))
+	got = slices.Collect(Gradient([]Color{stops[0], stops[2]}, []float64{0, 1}, Oklab, 7))
+	for i := range want {
+		if !approxValues(got[i].Values, want[i].Values, 1e-12) || math.Abs(got[i].Alpha-want[i].Alpha) > 1e-12 {
+			t.Errorf("color %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// Positions outside the first and last stops take their colors, and
+	// coinciding stops create a hard transition.
+	got = slices.Collect(Gradient(
+		[]Color{stops[0], stops[0], stops[1], stops[1]},
+		[]float64{0.25, 0.5, 0.5, 0.75},
+		SRGB, 5))
+	for i, want := range []Color{stops[0], stops[0], stops[1], stops[1], stops[1]} {
+		if got[i] != want {
+			t.Errorf("color %d: got %v, want %v", i, got[i], want)
+		}
+	}
+
+	for _, positions := range [][]float64{{0, 1, 0.5}, {-0.1, 0.5, 1}, {0, 0.5, math.NaN()}, {0, 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("positions %v: expected panic", positions)
+				}
+			}()
+			Gradient(stops, positions, SRGB, 3)
+		}()
+	}
+}
+
+func TestStepSlice(t *testing.T) {
+	c1 := Make(SRGB, 1, 0, 0, 1)
+	c2 := Make(DisplayP3, 0, 0, 1, 0.5)
+	for _, num := range []int{2, 5, 17} {
+		got := StepSlice(&c1, &c2, Oklch, SRGB, num)
+		want := slices.Collect(Step(&c1, &c2, Oklch, SRGB, num))
+		if !slices.Equal(got, want) {
+			t.Errorf("%d steps: got %v, want %v", num, got, want)
+		}
+	}
+}
+
+func TestGradientSlice(t *testing.T) {
+	stops := []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0, 1, 0, 1),
+		Make(SRGB, 0, 0, 1, 1),
+	}
+	positions := []float64{0, 0.3, 1}
+	for _, num := range []int{2, 5, 17} {
+		got := GradientSlice(stops, positions, Oklab, num)
+		want := slices.Collect(Gradient(stops, positions, Oklab, num))
+		if !slices.Equal(got, want) {
+			t.Errorf("%d steps: got %v, want %v", num, got, want)
+		}
+	}
+}
+
+func TestSplineGradient(t *testing.T) {
+	stops := []Color{
+		Make(Oklab, 0.3, 0.1, -0.1, 1),
+		Make(Oklab, 0.8, -0.1, 0.05, 1),
+		Make(Oklab, 0.5, 0.05, 0.1, 0.5),
+		Make(Oklab, 0.9, 0, 0, 1),
+	}
+	const num = 31
+	spline := slices.Collect(SplineGradient(stops, Oklab, num))
+	if len(spline) != num {
+		t.Fatalf("got %d colors, want %d", len(spline), num)
+	}
+	for i := range stops {
+		got := spline[i*(num-1)/(len(stops)-1)]
+		if !approxValues(got.Values, stops[i].Values, 1e-12) || math.Abs(got.Alpha-stops[i].Alpha) > 1e-12 {
+			t.Errorf("stop %d: got %v, want %v", i, got, stops[i])
+		}
+	}
+
+	// The spline has no kinks at the stops, so its largest second difference
+	// is smaller than that of piecewise linear interpolation.
+	linear := slices.Collect(Gradient(stops, []float64{0, 1.0 / 3, 2.0 / 3, 1}, Oklab, num))
+	maxSecondDiff := func(colors []Color) float64 {
+		var m float64
+		for i := 1; i < len(colors)-1; i++ {
+			for j := range 3 {
+				d := colors[i-1].Values[j] - 2*colors[i].Values[j] + colors[i+1].Values[j]
+				m = max(m, math.Abs(d))
+			}
+		}
+		return m
+	}
+	if s, l := maxSecondDiff(spline), maxSecondDiff(linear); s >= l {
+		t.Errorf("got maximum second difference %g, want less than linear's %g", s, l)
+	}
+
+	// Hues take the shorter path around the hue circle.
+	hues := []Color{
+		Make(Oklch, 0.7, 0.1, 350, 1),
+		Make(Oklch, 0.7, 0.1, 10, 1),
+	}
+	for c := range SplineGradient(hues, Oklch, 5) {
+		if h := c.Values[2]; h > 10+1e-9 && h < 350-1e-9 {
+			t.Errorf("got hue %g, want hue between 350° and 10°", h)
+		}
+	}
+}
+
+func TestSplineGradientMissing(t *testing.T) {
+	// The missing lightness of the first stop is carried over to Oklab and
+	// filled in from the next stop, instead of being treated as zero.
+	stops := []Color{
+		Make(Oklch, math.NaN(), 0, 0, 1),
+		Make(Oklab, 0.6, 0, 0, 1),
+		Make(Oklab, 0.8, 0, 0, 1),
+	}
+	got := slices.Collect(SplineGradient(stops, Oklab, 3))
+	if l := got[0].Values[0]; math.Abs(l-0.6) > 1e-12 {
+		t.Errorf("got lightness %g, want 0.6", l)
+	}
+	// The same stops, all in the interpolation space, give the same result.
+	stops[0] = Make(Oklab, math.NaN(), 0, 0, 1)
+	want := slices.Collect(SplineGradient(stops, Oklab, 3))
+	for i := range got {
+		if !got[i].ApproxEqual(&want[i], Oklab, 1e-12) {
+			t.Errorf("step %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHueDifference(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{350, 10, 20},
+		{10, 350, -20},
+		{0, 180, 180},
+		{180, 0, 180},
+		{90, 90, 0},
+		{720, 30, 30},
+		{-30, 30, 60},
+		{30, 200, 170},
+		{30, 220, -170},
+	}
+	for _, tt := range tests {
+		if got := HueDifference(tt.a, tt.b); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("HueDifference(%g, %g) = %g, want %g", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMeanHue(t *testing.T) {
+	tests := []struct {
+		hues []float64
+		want float64
+	}{
+		{[]float64{350, 10}, 0},
+		{[]float64{10, 350}, 0},
+		{[]float64{90}, 90},
+		{[]float64{-90}, 270},
+		{[]float64{80, 90, 100}, 90},
+		{[]float64{340, 350, 10, 20}, 0},
+	}
+	for _, tt := range tests {
+		got := MeanHue(tt.hues)
+		if d := math.Abs(HueDifference(got, tt.want)); d > 1e-9 || got < 0 || got >= 360 {
+			t.Errorf("MeanHue(%v) = %g, want %g", tt.hues, got, tt.want)
+		}
+	}
+	if got := MeanHue([]float64{0, 180}); !math.IsNaN(got) {
+		t.Errorf("got %g for opposite hues, want NaN", got)
+	}
+}
+
+func TestMixWeighted(t *testing.T) {
+	colors := []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0, 0.5, 1, 1),
+		Make(Oklch, 0.7, 0.1, 350, 1),
+	}
+	for _, in := range []*Space{Oklab, Oklch, LinearSRGB} {
+		got := MixWeighted(colors, []float64{2, 2, 2}, in)
+		want := Average(colors, in)
+		if !approxValues(got.Values, want.Values, 1e-12) || math.Abs(got.Alpha-want.Alpha) > 1e-12 {
+			t.Errorf("%s: got %v, want %v", in.Name, got, want)
+		}
+	}
+
+	// A 2:1 weighting is the same as mixing at a third of the way.
+	c1 := Make(SRGB, 1, 0, 0, 1)
+	c2 := Make(SRGB, 0, 0, 1, 0.25)
+	got := MixWeighted([]Color{c1, c2}, []float64{2, 1}, Oklab)
+	want := MixPremultiplied(&c1, &c2, Oklab, 1.0/3)
+	if !approxValues(got.Values, want.Values, 1e-12) || math.Abs(got.Alpha-want.Alpha) > 1e-12 {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Hues use the circular mean.
+	got = MixWeighted([]Color{Make(Oklch, 0.5, 0.1, 350, 1), Make(Oklch, 0.5, 0.1, 10, 1)}, []float64{3, 3}, Oklch)
+	if h := got.Values[2]; math.Abs(HueDifference(h, 0)) > 1e-9 {
+		t.Errorf("got hue %g, want 0", h)
+	}
+
+	// Missing components don't contribute.
+	got = MixWeighted([]Color{Make(Oklch, 0.5, 0.1, math.NaN(), 1), Make(Oklch, 0.7, 0.2, 120, 1)}, []float64{1, 1}, Oklch)
+	if want := [3]float64{0.6, 0.15, 120}; !approxValues(got.Values, want, 1e-12) {
+		t.Errorf("got %v, want %v", got.Values, want)
+	}
+
+	for _, weights := range [][]float64{{1, 2}, {1, -1, 1}, {0, 0, 0}, {1, math.NaN(), 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("weights %v: expected panic", weights)
+				}
+			}()
+			MixWeighted(colors, weights, Oklab)
+		}()
+	}
+}
+
+func TestAverage(t *testing.T) {
+	colors := []Color{
+		Make(LinearSRGB, 1, 0, 0, 1),
+		Make(LinearSRGB, 0, 1, 0, 0.5),
+	}
+	if got, want := Average(colors, LinearSRGB), Make(LinearSRGB, 0.5, 0.5, 0, 0.75); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	colors = []Color{
+		Make(Oklch, 0.6, 0.1, 350, 1),
+		Make(Oklch, 0.8, 0.2, 10, 1),
+	}
+	got := Average(colors, Oklch)
+	if math.Abs(got.Values[0]-0.7) > 1e-12 || math.Abs(got.Values[1]-0.15) > 1e-12 {
+		t.Errorf("got %v, want lightness 0.7 and chroma 0.15", got)
+	}
+	if h := got.Values[2]; min(h, 360-h) > 1e-9 {
+		t.Errorf("got hue %g, want 0", h)
+	}
+
+	// Opposite hues cancel out, like in MeanHue and MixWeighted.
+	colors = []Color{
+		Make(Oklch, 0.6, 0.1, 0, 1),
+		Make(Oklch, 0.8, 0.2, 180, 1),
+	}
+	if got := Average(colors, Oklch); !math.IsNaN(got.Values[2]) {
+		t.Errorf("got hue %g, want NaN", got.Values[2])
+	}
+	if got := MixWeighted(colors, []float64{1, 1}, Oklch); !math.IsNaN(got.Values[2]) {
+		t.Errorf("MixWeighted: got hue %g, want NaN", got.Values[2])
+	}
+	if got := MeanHue([]float64{0, 180}); !math.IsNaN(got) {
+		t.Errorf("MeanHue: got %g, want NaN", got)
+	}
+}
+
+func TestConvertInPlace(t *testing.T) {
+	for _, space := range []*Space{SRGB, LinearSRGB, Oklch, Lab, DisplayP3} {
+		c := Make(SRGB, 0.2, 0.5, 0.8, 0.5)
+		want := c.Convert(space)
+		c.ConvertInPlace(space)
+		if c != want {
+			t.Errorf("%s: got %v, want %v", space.ID, c, want)
+		}
+	}
+}
+
+func BenchmarkConvertInPlace(b *testing.B) {
+	colors := make([]Color, 256)
+	for i := range colors {
+		colors[i] = Make(SRGB, float64(i)/255, 0.5, 0.25, 1)
+	}
+	b.Run("Convert", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			for i := range colors {
+				colors[i] = colors[i].Convert(Oklch)
+				colors[i] = colors[i].Convert(SRGB)
+			}
+		}
+	})
+	b.Run("ConvertInPlace", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			for i := range colors {
+				colors[i].ConvertInPlace(Oklch)
+				colors[i].ConvertInPlace(SRGB)
+			}
+		}
+	})
+}
+
+func TestGetSet(t *testing.T) {
+	c := Make(Oklch, 0.7, 0.1, 120, 1)
+	if v, ok := c.Get("Hue"); !ok || v != 120 {
+		t.Errorf("got hue (%g, %t), want (120, true)", v, ok)
+	}
+	c.Set("Hue", 240)
+	if c.Values != [3]float64{0.7, 0.1, 240} {
+		t.Errorf("got %v after setting hue", c.Values)
+	}
+
+	c = Make(SRGB, 0.1, 0.2, 0.3, 1)
+	if v, ok := c.Get("Green"); !ok || v != 0.2 {
+		t.Errorf("got green (%g, %t), want (0.2, true)", v, ok)
+	}
+	if _, ok := c.Get("Hue"); ok {
+		t.Errorf("sRGB has a hue coordinate")
+	}
+	if idx, ok := SRGB.CoordIndex("Blue"); !ok || idx != 2 {
+		t.Errorf("got index (%d, %t), want (2, true)", idx, ok)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("setting a nonexistent coordinate didn't panic")
+		}
+	}()
+	c.Set("Hue", 0)
+}
+
+func TestHWBHSLHSV(t *testing.T) {
+	tests := []struct {
+		c       Color
+		h, w, b float64
+		hslSat  float64
+		hsvVal  float64
+	}{
+		{Make(SRGB, 1, 0, 0, 1), 0, 0, 0, 1, 1},
+		{Make(SRGB, 0, 0.5, 0.5, 1), 180, 0, 0.5, 1, 0.5},
+		{Make(SRGB, 0.2, 0.6, 0.4, 1), 150, 0.2, 0.4, 0.5, 0.6},
+		{Make(SRGB, 0.6, 0.2, 0.4, 1), 330, 0.2, 0.4, 0.5, 0.6},
+		{Make(SRGB, 0.5, 0.5, 0.5, 1), 0, 0.5, 0.5, 0, 0.5},
+		{Make(SRGB, 1, 1, 1, 1), 0, 1, 0, 0, 1},
+	}
+	for _, tt := range tests {
+		h, w, b := tt.c.HWBComponents()
+		if math.Abs(h-tt.h) > 1e-9 || math.Abs(w-tt.w) > 1e-9 || math.Abs(b-tt.b) > 1e-9 {
+			t.Errorf("%v: got hwb(%g %g %g), want hwb(%g %g %g)", tt.c, h, w, b, tt.h, tt.w, tt.b)
+		}
+		if got := tt.c.HSLSaturation(); math.Abs(got-tt.hslSat) > 1e-9 {
+			t.Errorf("%v: got HSL saturation %g, want %g", tt.c, got, tt.hslSat)
+		}
+		if got := tt.c.HSVValue(); math.Abs(got-tt.hsvVal) > 1e-9 {
+			t.Errorf("%v: got HSV value %g, want %g", tt.c, got, tt.hsvVal)
+		}
+	}
+}
+
+func TestChroma(t *testing.T) {
+	for _, v := range []float64{0, 0.25, 0.5, 1} {
+		gray := Make(SRGB, v, v, v, 1)
+		if c := gray.ChromaLCh(); c > 1e-3 {
+			t.Errorf("%v: got LCh chroma %g, want approximately 0", gray, c)
+		}
+		if c := gray.ChromaOklch(); c > 1e-6 {
+			t.Errorf("%v: got Oklch chroma %g, want approximately 0", gray, c)
+		}
+	}
+
+	red := Make(SRGB, 1, 0, 0, 1)
+	if got, want := red.ChromaLCh(), red.Convert(LCh).Values[1]; got != want || got < 100 {
+		t.Errorf("got LCh chroma %g, want %g", got, want)
+	}
+	if got, want := red.ChromaOklch(), red.Convert(Oklch).Values[1]; got != want || got < 0.2 {
+		t.Errorf("got Oklch chroma %g, want %g", got, want)
+	}
+}
+
+func TestIsAchromatic(t *testing.T) {
+	grays := []Color{
+		Make(SRGB, 0, 0, 0, 1),
+		Make(SRGB, 0.5, 0.5, 0.5, 1),
+		Make(SRGB, 1, 1, 1, 1),
+		Make(DisplayP3, 0.3, 0.3, 0.3, 1),
+		Make(Lab, 50, 0, 0, 1),
+		Make(Oklch, 0.6, 0, 200, 1),
+	}
+	for _, c := range grays {
+		if !c.IsAchromatic() {
+			t.Errorf("%v isn't achromatic", c)
+		}
+	}
+
+	colors := []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0.5, 0.5, 0.52, 1),
+		Make(Oklch, 0.6, 0.001, 200, 1),
+	}
+	for _, c := range colors {
+		if c.IsAchromatic() {
+			t.Errorf("%v is achromatic", c)
+		}
+	}
+}
+
+func TestAdjustOklch(t *testing.T) {
+	base := Make(SRGB, 0.2, 0.5, 0.7, 1)
+	lch := base.Convert(Oklch)
+
+	lighter := base.Lighten(0.1)
+	if lighter.Space != SRGB {
+		t.Errorf("got space %s, want sRGB", lighter.Space.ID)
+	}
+	llch := lighter.Convert(Oklch)
+	if math.Abs(llch.Values[0]-(lch.Values[0]+0.1)) > 1e-9 {
+		t.Errorf("got lightness %g, want %g", llch.Values[0], lch.Values[0]+0.1)
+	}
+	if d := hueDistance(llch.Values[2], lch.Values[2]); d > 1e-6 {
+		t.Errorf("lightening changed the hue by %g°", d)
+	}
+	white := base.Lighten(2)
+	if white = white.Convert(Oklch); math.Abs(white.Values[0]-1) > 1e-9 {
+		t.Errorf("lightness wasn't clamped: got %g", white.Values[0])
+	}
+	black := base.Darken(2)
+	if black = black.Convert(Oklch); math.Abs(black.Values[0]) > 1e-9 {
+		t.Errorf("lightness wasn't clamped: got %g", black.Values[0])
+	}
+
+	saturated := base.Saturate(0.05)
+	saturated = saturated.Convert(Oklch)
+	if math.Abs(saturated.Values[1]-(lch.Values[1]+0.05)) > 1e-9 {
+		t.Errorf("got chroma %g, want %g", saturated.Values[1], lch.Values[1]+0.05)
+	}
+	if gray := base.Desaturate(1); !gray.IsAchromatic() {
+		t.Errorf("desaturating fully didn't produce a gray: %v", gray)
+	}
+
+	want := Harmony(&base, Complementary)[0]
+	got := base.RotateHue(180)
+	for i := range got.Values {
+		if math.Abs(got.Values[i]-want.Values[i]) > 1e-12 {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+	got = base.RotateHue(-720)
+	if got = got.Convert(Oklch); hueDistance(got.Values[2], lch.Values[2]) > 1e-6 {
+		t.Errorf("rotating by -720° changed the hue to %g", got.Values[2])
+	}
+}
+
+func TestWithAlpha(t *testing.T) {
+	c := Make(Oklch, 0.5, 0.1, 200, 0.5)
+	tests := []struct {
+		alpha float64
+		want  float64
+	}{
+		{0.25, 0.25},
+		{1.5, 1},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		got := c.WithAlpha(tt.alpha)
+		if want := (Color{c.Values, c.Space, tt.want}); got != want {
+			t.Errorf("WithAlpha(%g): got %v, want %v", tt.alpha, got, want)
+		}
+	}
+
+	if got, want := c.Opaque(), (Color{c.Values, c.Space, 1}); got != want {
+		t.Errorf("Opaque: got %v, want %v", got, want)
+	}
+}
+
+func TestExposure(t *testing.T) {
+	for _, c := range []Color{
+		Make(SRGB, 0.2, 0.4, 0.6, 1),
+		Make(Oklch, 0.5, 0.1, 120, 0.5),
+	} {
+		brighter := c.Exposure(1)
+		if brighter.Space != c.Space || brighter.Alpha != c.Alpha {
+			t.Errorf("%v: got %v, want same space and alpha", c, brighter)
+		}
+		y1 := c.Convert(XYZ_D65).Values[1]
+		y2 := brighter.Convert(XYZ_D65).Values[1]
+		if math.Abs(y2-2*y1) > 1e-12 {
+			t.Errorf("%v: got luminance %g after +1 stop, want %g", c, y2, 2*y1)
+		}
+
+		if got := c.Exposure(0); !approxValues(got.Values, c.Values, 1e-12) {
+			t.Errorf("%v: got %v after 0 stops", c, got)
+		}
+		back := brighter.Exposure(-1)
+		if !approxValues(back.Values, c.Values, 1e-9) {
+			t.Errorf("%v: got %v after +1 and -1 stops", c, back)
+		}
+	}
+}
+
+func TestGamma(t *testing.T) {
+	for _, c := range []Color{
+		Make(SRGB, 0.2, 0.4, 0.6, 1),
+		Make(Lab, 50, 20, -30, 1),
+	} {
+		if got := c.Gamma(1); got.Space != c.Space || !approxValues(got.Values, c.Values, 1e-9) {
+			t.Errorf("%v: got %v for gamma 1", c, got)
+		}
+	}
+
+	c := Make(SRGB, 0.25, 0, 1, 1)
+	if got, want := c.Gamma(2), Make(SRGB, 0.5, 0, 1, 1); !approxValues(got.Values, want.Values, 1e-12) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApproxEqual(t *testing.T) {
+	c1 := Make(SRGB, 0.2, 0.5, 0.7, 1)
+	for _, space := range []*Space{Oklch, Lab, DisplayP3, XYZ_D50} {
+		c2 := c1.Convert(space)
+		if !c1.ApproxEqual(&c2, SRGB, 1e-9) {
+			t.Errorf("%v and %v aren't equal", c1, c2)
+		}
+	}
+
+	near := Make(SRGB, 0.2, 0.5, 0.701, 1)
+	if c1.ApproxEqual(&near, SRGB, 1e-4) {
+		t.Errorf("%v and %v are equal with tolerance 1e-4", c1, near)
+	}
+	if !c1.ApproxEqual(&near, SRGB, 1e-2) {
+		t.Errorf("%v and %v aren't equal with tolerance 1e-2", c1, near)
+	}
+
+	transparent := c1.WithAlpha(0.5)
+	if c1.ApproxEqual(&transparent, SRGB, 1e-4) {
+		t.Errorf("colors with different alpha are equal")
+	}
+
+	h1 := Make(Oklch, 0.5, 0.1, 359.5, 1)
+	h2 := Make(Oklch, 0.5, 0.1, 0.5, 1)
+	if !h1.ApproxEqual(&h2, Oklch, 1) {
+		t.Errorf("hues 359.5° and 0.5° aren't within 1°")
+	}
+	if h1.ApproxEqual(&h2, Oklch, 0.5) {
+		t.Errorf("hues 359.5° and 0.5° are within 0.5°")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	c := Make(SRGB, 0.25, 0.5, 1, 1)
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{c.String(), "color(srgb 0.250000 0.500000 1.000000)"},
+		{fmt.Sprint(c), "color(srgb 0.250000 0.500000 1.000000)"},
+		{fmt.Sprintf("%.2v", c), "color(srgb 0.25 0.50 1.00)"},
+		{fmt.Sprintf("%.0s", c), "color(srgb 0 0 1)"},
+		{c.FormatPrec(-1), "color(srgb 0.25 0.5 1)"},
+		{c.WithAlpha(0.5).FormatPrec(1), "color(srgb 0.2 0.5 1.0 / 0.5)"},
+		{Make(Oklch, 0.5, 0.1, 120, 1).FormatPrec(-1), "color(--oklch 0.5 0.1 120)"},
+		{fmt.Sprintf("%d", c), "%!d(color.Color=color(srgb 0.250000 0.500000 1.000000))"},
+		{fmt.Sprintf("%q", c), `"color(srgb 0.250000 0.500000 1.000000)"`},
+		{fmt.Sprintf("%.1q", c), `"color(srgb 0.2 0.5 1.0)"`},
+		{fmt.Sprintf("%28.2v|", c), "  color(srgb 0.25 0.50 1.00)|"},
+		{fmt.Sprintf("%-28.2s|", c), "color(srgb 0.25 0.50 1.00)  |"},
+		{fmt.Sprintf("%x", Make(SRGB, 0, 0, 0, 1)), fmt.Sprintf("%x", "color(srgb 0.000000 0.000000 0.000000)")},
+		{fmt.Sprintf("%#v", c), fmt.Sprintf("color.Color{Values:[3]float64{0.25, 0.5, 1}, Space:(*color.Space)(%p), Alpha:1}", SRGB)},
+		{fmt.Sprintf("%#v", Color{}), "color.Color{Values:[3]float64{0, 0, 0}, Space:(*color.Space)(nil), Alpha:0}"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestMissingComponents(t *testing.T) {
+	missingHue, _ := Parse("oklch(0.5 0.2 none)")
+	other := Make(Oklch, 0.8, 0.1, 120, 1)
+
+	got := Mix(&missingHue, &other, Oklch, 0.5)
+	if want := [3]float64{0.65, 0.15, 120}; !approxValues(got.Values, want, 1e-12) {
+		t.Errorf("got %v, want %v", got.Values, want)
+	}
+	for c := range StepHue(&missingHue, &other, Oklch, Oklch, 5, ShorterHue) {
+		if c.Values[2] != 120 {
+			t.Errorf("got hue %g, want 120", c.Values[2])
+		}
+	}
+
+	// Missing components are carried forward to analogous components of the
+	// interpolation space.
+	got = Mix(&missingHue, &other, LCh, 0.5)
+	otherLCh := other.Convert(LCh)
+	if math.Abs(got.Values[2]-otherLCh.Values[2]) > 1e-9 {
+		t.Errorf("got hue %g, want %g", got.Values[2], otherLCh.Values[2])
+	}
+
+	// Missing in both colors stays missing.
+	missingBoth := Make(Oklch, 0.8, 0.1, math.NaN(), math.NaN())
+	got = Mix(&missingHue, &missingBoth, Oklch, 0.5)
+	if !math.IsNaN(got.Values[2]) || math.IsNaN(got.Values[0]) {
+		t.Errorf("got %v, want only the hue to be missing", got.Values)
+	}
+	if got.Alpha != 1 {
+		t.Errorf("got alpha %g, want 1", got.Alpha)
+	}
+
+	// Converting treats missing components as zero.
+	c, _ := Parse("color(srgb none 0.5 1)")
+	want := Make(SRGB, 0, 0.5, 1, 1)
+	if got, want := c.Convert(Oklab), want.Convert(Oklab); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	c.ConvertInPlace(Oklab)
+	if got, want := c, want.Convert(Oklab); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func approxValues(a, b [3]float64, ϵ float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > ϵ {
+			return false
+		}
+	}
+	return true
 }
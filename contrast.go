@@ -4,7 +4,6 @@ package color
 // APCA
 // Lstar
 // Michelson
-// WCAG21
 // Weber
 // DeltaPhiStar
 
@@ -12,10 +11,17 @@ func luminance(c *Color) float64 {
 	return c.Convert(XYZ_D65).Values[1]
 }
 
+// RelativeLuminance returns the relative luminance of c, that is, the Y
+// component of c in [XYZ_D65]. This is the luminance used by WCAG. Negative
+// luminances are clamped to zero.
+func RelativeLuminance(c *Color) float64 {
+	return max(luminance(c), 0)
+}
+
 // ContrastWeber computes the Weber luminance contrast.
 func ContrastWeber(c1, c2 *Color) float64 {
-	y1 := max(luminance(c1), 0)
-	y2 := max(luminance(c2), 0)
+	y1 := RelativeLuminance(c1)
+	y2 := RelativeLuminance(c2)
 
 	if y2 > y1 {
 		y1, y2 = y2, y1
@@ -33,8 +39,8 @@ func ContrastWeber(c1, c2 *Color) float64 {
 
 // ContrastMichelson computes the Michelson contrast.
 func ContrastMichelson(c1, c2 *Color) float64 {
-	y1 := max(luminance(c1), 0)
-	y2 := max(luminance(c2), 0)
+	y1 := RelativeLuminance(c1)
+	y2 := RelativeLuminance(c2)
 
 	if y2 > y1 {
 		y1, y2 = y2, y1
@@ -45,3 +51,37 @@ func ContrastMichelson(c1, c2 *Color) float64 {
 	}
 	return (y1 - y2) / (y1 + y2)
 }
+
+// ContrastWCAG21 computes the contrast ratio as defined by the Web Content
+// Accessibility Guidelines (WCAG) 2.1. The result is in the range [1, 21].
+func ContrastWCAG21(c1, c2 *Color) float64 {
+	y1 := RelativeLuminance(c1)
+	y2 := RelativeLuminance(c2)
+
+	if y2 > y1 {
+		y1, y2 = y2, y1
+	}
+
+	return (y1 + 0.05) / (y2 + 0.05)
+}
+
+// BestTextColor returns the candidate with the highest WCAG 2.1 contrast (see
+// [ContrastWCAG21]) against the background color bg. If multiple candidates
+// have the same contrast, the first one is returned. If no candidates are
+// provided, it chooses between black and white.
+func BestTextColor(bg *Color, candidates ...*Color) *Color {
+	if len(candidates) == 0 {
+		black := Make(SRGB, 0, 0, 0, 1)
+		white := Make(SRGB, 1, 1, 1, 1)
+		candidates = []*Color{&black, &white}
+	}
+	best := candidates[0]
+	bestContrast := ContrastWCAG21(bg, best)
+	for _, c := range candidates[1:] {
+		if contrast := ContrastWCAG21(bg, c); contrast > bestContrast {
+			best = c
+			bestContrast = contrast
+		}
+	}
+	return best
+}
@@ -0,0 +1,70 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrastWCAG21(t *testing.T) {
+	black := Make(SRGB, 0, 0, 0, 1)
+	white := Make(SRGB, 1, 1, 1, 1)
+	if got := ContrastWCAG21(&black, &white); math.Abs(got-21) > 1e-9 {
+		t.Errorf("got %g, want 21", got)
+	}
+	if got := ContrastWCAG21(&white, &black); math.Abs(got-21) > 1e-9 {
+		t.Errorf("got %g, want 21", got)
+	}
+	if got := ContrastWCAG21(&white, &white); got != 1 {
+		t.Errorf("got %g, want 1", got)
+	}
+}
+
+func TestBestTextColor(t *testing.T) {
+	// The luminance at which black and white have the same contrast.
+	boundary := math.Sqrt(1.05*0.05) - 0.05
+
+	tests := []struct {
+		bg    Color
+		white bool
+	}{
+		{Make(SRGB, 0.1, 0.1, 0.2, 1), true},
+		{Make(SRGB, 0.9, 0.9, 0.8, 1), false},
+		{Make(LinearSRGB, boundary-0.001, boundary-0.001, boundary-0.001, 1), true},
+		{Make(LinearSRGB, boundary+0.001, boundary+0.001, boundary+0.001, 1), false},
+	}
+	for _, tt := range tests {
+		got := BestTextColor(&tt.bg)
+		want := Make(SRGB, 0, 0, 0, 1)
+		if tt.white {
+			want = Make(SRGB, 1, 1, 1, 1)
+		}
+		if *got != want {
+			t.Errorf("%v: got %v, want %v", tt.bg, *got, want)
+		}
+	}
+
+	bg := Make(SRGB, 0.2, 0.2, 0.2, 1)
+	yellow := Make(SRGB, 1, 1, 0, 1)
+	navy := Make(SRGB, 0, 0, 0.5, 1)
+	if got := BestTextColor(&bg, &navy, &yellow); got != &yellow {
+		t.Errorf("got %v, want %v", *got, yellow)
+	}
+}
+
+func TestRelativeLuminance(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want float64
+	}{
+		{Make(SRGB, 1, 1, 1, 1), 1},
+		{Make(SRGB, 0, 0, 0, 1), 0},
+		{Make(SRGB, 1, 0, 0, 1), 0.2126},
+		// Negative luminance is clamped.
+		{Make(LinearSRGB, -1, -1, -1, 1), 0},
+	}
+	for _, tt := range tests {
+		if got := RelativeLuminance(&tt.c); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("%v: got %g, want %g", tt.c, got, tt.want)
+		}
+	}
+}
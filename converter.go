@@ -0,0 +1,104 @@
+package color
+
+// A Converter converts coordinates from one color space to another. It
+// precomputes the path between the two spaces, making it cheaper than repeated
+// calls to [Space.Convert] or [Color.Convert] when converting many colors.
+//
+// Consecutive conversion steps that are plain matrix multiplications, such as
+// those between linear RGB spaces and XYZ, are collapsed into a single matrix.
+// Because of this, results may differ from those of [Space.Convert] by a small
+// amount of floating point error.
+//
+// A Converter is safe for concurrent use.
+type Converter struct {
+	from  *Space
+	to    *Space
+	steps []convStep
+}
+
+// convStep is a single step of a conversion. If m is non-nil, the step is a
+// multiplication with m, otherwise it is a call to fn.
+type convStep struct {
+	fn func(c *[3]float64) [3]float64
+	m  *[3][3]float64
+}
+
+// NewConverter returns a converter from the space from to the space to.
+func NewConverter(from, to *Space) *Converter {
+	cv := &Converter{from: from, to: to}
+	if from == to {
+		return cv
+	}
+
+	push := func(fn func(c *[3]float64) [3]float64, m *[3][3]float64) {
+		if m == nil {
+			cv.steps = append(cv.steps, convStep{fn: fn})
+			return
+		}
+		if n := len(cv.steps); n > 0 && cv.steps[n-1].m != nil {
+			// Applying A and then B is the same as applying B·A.
+			mm := MulMatMat(m, cv.steps[n-1].m)
+			cv.steps[n-1].m = &mm
+			return
+		}
+		cv.steps = append(cv.steps, convStep{m: m})
+	}
+
+	connIdx := from.connection(to)
+	for i := len(from.path) - 1; i > connIdx; i-- {
+		cs := from.path[i]
+		push(cs.ToBase, cs.toBaseMatrix)
+	}
+	for i := connIdx + 1; i < len(to.path); i++ {
+		cs := to.path[i]
+		push(cs.FromBase, cs.fromBaseMatrix)
+	}
+	return cv
+}
+
+// From returns the source space of the converter.
+func (cv *Converter) From() *Space { return cv.from }
+
+// To returns the destination space of the converter.
+func (cv *Converter) To() *Space { return cv.to }
+
+// Convert converts coordinates from the converter's source space to its
+// destination space.
+func (cv *Converter) Convert(coords [3]float64) [3]float64 {
+	for i := range cv.steps {
+		step := &cv.steps[i]
+		if step.m != nil {
+			coords = MulVecMat(&coords, step.m)
+		} else {
+			coords = step.fn(&coords)
+		}
+	}
+	return coords
+}
+
+// RGBToRGBMatrix returns the matrix that converts linear coordinates in the
+// space from to linear coordinates in the space to, including chromatic
+// adaptation if the spaces' white points differ. Both spaces must be related
+// to XYZ purely by matrix multiplication, as is the case for linear RGB spaces
+// and the XYZ spaces. If they aren't, ok will be false.
+func RGBToRGBMatrix(from, to *Space) (m [3][3]float64, ok bool) {
+	if !isMatrixSpace(from) || !isMatrixSpace(to) {
+		return m, false
+	}
+	m = [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for _, step := range NewConverter(from, to).steps {
+		m = MulMatMat(step.m, &m)
+	}
+	return m, true
+}
+
+// isMatrixSpace reports whether every step between the root of the color space
+// tree and cs is a matrix multiplication.
+func isMatrixSpace(cs *Space) bool {
+	for _, p := range cs.path[1:] {
+		if p.toBaseMatrix == nil || p.fromBaseMatrix == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+var converterTestSpaces = []*Space{
+	XYZ_D65, XYZ_D50, XyY, LinearSRGB, SRGB, LinearDisplayP3, DisplayP3,
+	Oklab, Oklch, Lab, LCh, LinearProPhoto, ProPhoto, LinearRec2020, Rec2020,
+	YCbCr601, YCbCr2020,
+}
+
+func TestConverter(t *testing.T) {
+	inputs := [][3]float64{
+		{0, 0, 0},
+		{1, 1, 1},
+		{0.2, 0.5, 0.8},
+		{0.9, 0.1, 0.3},
+	}
+	for _, from := range converterTestSpaces {
+		for _, to := range converterTestSpaces {
+			cv := NewConverter(from, to)
+			for _, in := range inputs {
+				// Use a value that is valid in the source space.
+				c := Make(SRGB, in[0], in[1], in[2], 1)
+				c = c.Convert(from)
+				want := from.Convert(to, c.Values)
+				got := cv.Convert(c.Values)
+				for i := range got {
+					if math.Abs(got[i]-want[i]) > 1e-9 {
+						t.Errorf("%s -> %s: converting %v: got %v, want %v",
+							from.ID, to.ID, c.Values, got, want)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConverterCollapse(t *testing.T) {
+	// sRGB -> Display P3 goes through linear sRGB, XYZ, and linear P3. The two
+	// RGB matrices should be collapsed into one.
+	cv := NewConverter(SRGB, DisplayP3)
+	if len(cv.steps) != 3 {
+		t.Errorf("got %d steps, want 3", len(cv.steps))
+	}
+
+	// Linear RGB to linear RGB is a single matrix.
+	cv = NewConverter(LinearSRGB, LinearDisplayP3)
+	if len(cv.steps) != 1 || cv.steps[0].m == nil {
+		t.Errorf("got %d steps, want a single matrix", len(cv.steps))
+	}
+
+	cv = NewConverter(SRGB, SRGB)
+	if len(cv.steps) != 0 {
+		t.Errorf("got %d steps, want 0", len(cv.steps))
+	}
+}
+
+func BenchmarkConverter(b *testing.B) {
+	c := Make(SRGB, 0.2, 0.5, 0.8, 1)
+	b.Run("Color.Convert", func(b *testing.B) {
+		for range b.N {
+			c.Convert(DisplayP3)
+		}
+	})
+	b.Run("Converter", func(b *testing.B) {
+		cv := NewConverter(SRGB, DisplayP3)
+		for range b.N {
+			cv.Convert(c.Values)
+		}
+	})
+}
+
+func TestRGBToRGBMatrix(t *testing.T) {
+	matrixSpaces := []*Space{
+		XYZ_D65, XYZ_D50, LinearSRGB, LinearDisplayP3, LinearProPhoto, LinearRec2020,
+	}
+	inputs := [][3]float64{
+		{0, 0, 0},
+		{1, 1, 1},
+		{0.2, 0.5, 0.8},
+		{0.9, 0.1, 0.3},
+	}
+	for _, from := range matrixSpaces {
+		for _, to := range matrixSpaces {
+			m, ok := RGBToRGBMatrix(from, to)
+			if !ok {
+				t.Errorf("%s -> %s: got ok == false", from.ID, to.ID)
+				continue
+			}
+			for _, in := range inputs {
+				want := from.Convert(to, in)
+				got := MulVecMat(&in, &m)
+				for i := range got {
+					if math.Abs(got[i]-want[i]) > 1e-12 {
+						t.Errorf("%s -> %s: converting %v: got %v, want %v",
+							from.ID, to.ID, in, got, want)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for _, pair := range [][2]*Space{{SRGB, LinearDisplayP3}, {LinearSRGB, Oklab}, {Lab, XYZ_D50}} {
+		if _, ok := RGBToRGBMatrix(pair[0], pair[1]); ok {
+			t.Errorf("%s -> %s: got ok == true", pair[0].ID, pair[1].ID)
+		}
+	}
+}
+
+func TestConverterReplacedFunctions(t *testing.T) {
+	// A copy of a matrix space whose conversion functions have been replaced
+	// must not keep using the original matrices.
+	cs := *LinearSRGB
+	cs.ID = "test-replaced-functions"
+	cs.ToBase = func(c *[3]float64) [3]float64 {
+		v := [3]float64{c[0] * 2, c[1] * 2, c[2] * 2}
+		return LinearSRGB.ToBase(&v)
+	}
+	cs.FromBase = func(c *[3]float64) [3]float64 {
+		v := LinearSRGB.FromBase(c)
+		return [3]float64{v[0] / 2, v[1] / 2, v[2] / 2}
+	}
+	cs.Init()
+
+	in := [3]float64{0.2, 0.4, 0.3}
+	want := cs.Convert(LinearDisplayP3, in)
+	if got := NewConverter(&cs, LinearDisplayP3).Convert(in); !approxValues(got, want, 1e-12) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, ok := RGBToRGBMatrix(&cs, LinearDisplayP3); ok {
+		t.Errorf("got ok == true for a space with replaced functions")
+	}
+}
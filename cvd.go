@@ -0,0 +1,135 @@
+package color
+
+// CVDKind identifies a kind of color vision deficiency.
+type CVDKind int
+
+const (
+	// Protanopia is the absence of functioning long-wavelength (L) cones.
+	Protanopia CVDKind = iota
+	// Deuteranopia is the absence of functioning medium-wavelength (M) cones.
+	Deuteranopia
+	// Tritanopia is the absence of functioning short-wavelength (S) cones.
+	Tritanopia
+)
+
+// cvdAnchors are the wavelengths, in nanometers, of the monochromatic stimuli
+// that, together with the white point, span the two half-planes onto which
+// Brettel et al. project colors.
+var cvdAnchors = [...][2]float64{
+	Protanopia:   {475, 575},
+	Deuteranopia: {475, 575},
+	Tritanopia:   {485, 660},
+}
+
+// SimulateCVD simulates how c is perceived by a viewer with the color vision
+// deficiency kind. Severity ranges from 0, normal color vision, to 1, complete
+// dichromacy, and linearly interpolates between the two in between. The result
+// is returned in c's color space and has c's alpha.
+//
+// The simulation follows Brettel, Viénot, and Mollon (1997). Colors are
+// converted to LMS, using the Hunt-Pointer-Estevez cone fundamentals (see
+// [VonKries]), and the missing cone response is reconstructed from the
+// remaining two by projecting the color onto one of two half-planes. The planes
+// are spanned by the white point and, for protanopia and deuteranopia,
+// monochromatic stimuli of 475 nm and 575 nm, or, for tritanopia, 485 nm and
+// 660 nm. These are stimuli that dichromats and trichromats perceive
+// identically. The result may lie outside the gamut of c's color space.
+func SimulateCVD(c *Color, kind CVDKind, severity float64) Color {
+	xyz := c.Convert(XYZ_D65)
+	lms := MulVecMat(&xyz.Values, &VonKries.ToCone)
+	sim := simulateCVDLMS(lms, kind)
+	for i := range lms {
+		lms[i] = lerp(lms[i], sim[i], severity)
+	}
+	xyz.Values = MulVecMat(&lms, &VonKries.FromCone)
+	return xyz.Convert(c.Space)
+}
+
+// simulateCVDLMS returns the LMS cone responses of a dichromat of the given
+// kind.
+func simulateCVDLMS(lms [3]float64, kind CVDKind) [3]float64 {
+	white := XYZ_D65.White.XYZ()
+	e := MulVecMat(&white, &VonKries.ToCone)
+
+	// Index of the missing cone response and of the two responses whose ratio
+	// selects the half-plane the color is projected onto.
+	var missing, p, q int
+	switch kind {
+	case Protanopia:
+		missing, p, q = 0, 2, 1
+	case Deuteranopia:
+		missing, p, q = 1, 2, 0
+	case Tritanopia:
+		missing, p, q = 2, 1, 0
+	default:
+		panic("invalid CVD kind")
+	}
+
+	anchor := cvdAnchors[kind][0]
+	if lms[p]*e[q] < e[p]*lms[q] {
+		anchor = cvdAnchors[kind][1]
+	}
+	axyz := ObserverCIE1931TwoDeg.ColorMatchingFunctions(anchor)
+	a := MulVecMat(&axyz, &VonKries.ToCone)
+
+	// The normal of the plane through the origin, the white point, and the
+	// anchor stimulus.
+	n := [3]float64{
+		e[1]*a[2] - e[2]*a[1],
+		e[2]*a[0] - e[0]*a[2],
+		e[0]*a[1] - e[1]*a[0],
+	}
+	var sum float64
+	for i := range lms {
+		if i != missing {
+			sum += n[i] * lms[i]
+		}
+	}
+	lms[missing] = -sum / n[missing]
+	return lms
+}
+
+// daltonizeMatrices redistribute the information lost to a color vision
+// deficiency onto channels that are still perceived. These are the matrices
+// of Fidaner, Lin, and Ozguven, which treat protanopia and deuteranopia alike.
+var daltonizeMatrices = [...][3][3]float64{
+	Protanopia: {
+		{0, 0, 0},
+		{0.7, 1, 0},
+		{0.7, 0, 1},
+	},
+	Deuteranopia: {
+		{0, 0, 0},
+		{0.7, 1, 0},
+		{0.7, 0, 1},
+	},
+	Tritanopia: {
+		{1, 0, 0.7},
+		{0, 1, 0.7},
+		{0, 0, 0},
+	},
+}
+
+// Daltonize adjusts c to be easier to distinguish from other colors for a
+// viewer with the color vision deficiency kind, at the given severity (see
+// [SimulateCVD]). The difference between c and its simulated appearance, which
+// is the information the viewer can't perceive, is shifted onto the channels
+// the viewer can still perceive and added to c. The computation is carried out
+// in [SRGB], and the result is returned in c's color space. Neutral colors are
+// left unchanged.
+func Daltonize(c *Color, kind CVDKind, severity float64) Color {
+	if kind < 0 || int(kind) >= len(daltonizeMatrices) {
+		panic("invalid CVD kind")
+	}
+	rgb := c.Convert(SRGB)
+	sim := SimulateCVD(&rgb, kind, severity)
+	var diff [3]float64
+	for i := range diff {
+		diff[i] = rgb.Values[i] - sim.Values[i]
+	}
+	shift := MulVecMat(&diff, &daltonizeMatrices[kind])
+	for i := range rgb.Values {
+		rgb.Values[i] += shift[i]
+	}
+	return rgb.Convert(c.Space)
+}
@@ -0,0 +1,89 @@
+package color
+
+import "testing"
+
+func TestSimulateCVD(t *testing.T) {
+	kinds := []CVDKind{Protanopia, Deuteranopia, Tritanopia}
+	for _, kind := range kinds {
+		for _, c := range []Color{
+			Make(SRGB, 1, 0, 0, 1),
+			Make(SRGB, 0.2, 0.6, 0.3, 0.5),
+			Make(Oklch, 0.7, 0.1, 200, 1),
+		} {
+			got := SimulateCVD(&c, kind, 0)
+			if got.Space != c.Space || got.Alpha != c.Alpha || !approxValues(got.Values, c.Values, 1e-9) {
+				t.Errorf("kind %d, severity 0: got %v, want %v", kind, got, c)
+			}
+		}
+
+		// Dichromats and trichromats perceive neutral colors identically.
+		for _, v := range []float64{0, 0.2, 0.5, 1} {
+			gray := Make(SRGB, v, v, v, 1)
+			if got := SimulateCVD(&gray, kind, 1); !approxValues(got.Values, gray.Values, 1e-9) {
+				t.Errorf("kind %d: got %v, want %v", kind, got, gray)
+			}
+		}
+	}
+
+	// To a protanope, red appears as a darker, desaturated yellow-brown.
+	red := Make(SRGB, 1, 0, 0, 1)
+	redLCh := red.Convert(Oklch)
+	sim := SimulateCVD(&red, Protanopia, 1)
+	simLCh := sim.Convert(Oklch)
+	if h := simLCh.Values[2]; h < 80 || h > 110 {
+		t.Errorf("got hue %g, want a yellowish hue", h)
+	}
+	if simLCh.Values[0] >= redLCh.Values[0] {
+		t.Errorf("got lightness %g, want less than %g", simLCh.Values[0], redLCh.Values[0])
+	}
+	if simLCh.Values[1] >= redLCh.Values[1] {
+		t.Errorf("got chroma %g, want less than %g", simLCh.Values[1], redLCh.Values[1])
+	}
+
+	// Partial severity interpolates linearly in linear light.
+	linRed := red.Convert(LinearSRGB)
+	linSim := SimulateCVD(&linRed, Protanopia, 1)
+	half := SimulateCVD(&linRed, Protanopia, 0.5)
+	for i := range half.Values {
+		want := (linRed.Values[i] + linSim.Values[i]) / 2
+		if d := half.Values[i] - want; d > 1e-9 || d < -1e-9 {
+			t.Errorf("severity 0.5: got %v, want %g for component %d", half, want, i)
+		}
+	}
+}
+
+func TestDaltonize(t *testing.T) {
+	kinds := []CVDKind{Protanopia, Deuteranopia, Tritanopia}
+	for _, kind := range kinds {
+		for _, v := range []float64{0, 0.3, 0.5, 1} {
+			gray := Make(SRGB, v, v, v, 1)
+			if got := Daltonize(&gray, kind, 1); !approxValues(got.Values, gray.Values, 1e-9) {
+				t.Errorf("kind %d: got %v, want %v", kind, got, gray)
+			}
+		}
+
+		c := Make(Oklch, 0.6, 0.15, 30, 0.5)
+		if got := Daltonize(&c, kind, 0); got.Space != c.Space || got.Alpha != c.Alpha || !approxValues(got.Values, c.Values, 1e-9) {
+			t.Errorf("kind %d, severity 0: got %v, want %v", kind, got, c)
+		}
+	}
+
+	// A red and a green that are easily confused by protanopes and
+	// deuteranopes should be further apart after daltonization.
+	red := Make(SRGB, 0.8, 0.3, 0.2, 1)
+	green := Make(SRGB, 0.45, 0.5, 0.15, 1)
+	for _, kind := range kinds[:2] {
+		simRed := SimulateCVD(&red, kind, 1)
+		simGreen := SimulateCVD(&green, kind, 1)
+		before := DeltaEOK(&simRed, &simGreen)
+
+		dRed := Daltonize(&red, kind, 1)
+		dGreen := Daltonize(&green, kind, 1)
+		simRed = SimulateCVD(&dRed, kind, 1)
+		simGreen = SimulateCVD(&dGreen, kind, 1)
+		after := DeltaEOK(&simRed, &simGreen)
+		if after <= before {
+			t.Errorf("kind %d: got difference %g after daltonization, want more than %g", kind, after, before)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package color
 
-import "math"
+import (
+	"math"
+	"slices"
+)
 
 // TODO:
 // 2000
@@ -49,3 +52,75 @@ func DeltaEOK2(reference, sample *Color) float64 {
 	Δ2 := 2 * (ref.Values[2] - s.Values[2])
 	return math.Hypot(math.Hypot(Δ0, Δ1), Δ2)
 }
+
+// DeltaECAM16 computes the color difference using the Euclidean distance in
+// CAM16-UCS, for colors viewed in the environment env. If env is nil,
+// [CAM16SRGBEnvironment] is used, in which case the result is the same as
+// DeltaDistance(reference, sample, CAM16UCS).
+func DeltaECAM16(reference, sample *Color, env *CAM16Environment) float64 {
+	vc := cam16SRGBViewingConditions
+	if env != nil {
+		vc = env.viewingConditions()
+	}
+	ref := reference.Convert(XYZ_D65)
+	s := sample.Convert(XYZ_D65)
+	u1 := xyzToCAM16UCS(ref.Values, vc)
+	u2 := xyzToCAM16UCS(s.Values, vc)
+	Δ0 := u1[0] - u2[0]
+	Δ1 := u1[1] - u2[1]
+	Δ2 := u1[2] - u2[2]
+	return math.Hypot(math.Hypot(Δ0, Δ1), Δ2)
+}
+
+// DeltaEFunc is the signature shared by the functions that compute the
+// perceptual difference between two colors, such as [DeltaEOK].
+type DeltaEFunc func(reference, sample *Color) float64
+
+// DeltaEImage computes the per-pixel color difference between two images,
+// storing the result in out. Both images are interpreted as pixels in the
+// provided color space and must have the same number of pixels. out must have
+// room for at least as many values as there are pixels.
+func DeltaEImage(a, b [][3]float64, space *Space, metric DeltaEFunc, out []float64) {
+	if len(a) != len(b) {
+		panic("images have different sizes")
+	}
+	if len(out) < len(a) {
+		panic("output buffer is too small")
+	}
+	ca := Color{Space: space, Alpha: 1}
+	cb := Color{Space: space, Alpha: 1}
+	for i := range a {
+		ca.Values = a[i]
+		cb.Values = b[i]
+		out[i] = metric(&ca, &cb)
+	}
+}
+
+// ImageDiffStats computes summary statistics of the per-pixel color difference
+// between two images, as computed by [DeltaEImage]. It returns the mean, the
+// 95th percentile, and the maximum of the differences, as well as the index of
+// the pixel with the largest difference. For empty images, worstIdx is -1.
+func ImageDiffStats(a, b [][3]float64, space *Space, metric DeltaEFunc) (mean, p95, max float64, worstIdx int) {
+	if len(a) == 0 {
+		return 0, 0, 0, -1
+	}
+	diffs := make([]float64, len(a))
+	DeltaEImage(a, b, space, metric, diffs)
+
+	var sum float64
+	for i, d := range diffs {
+		sum += d
+		if i == 0 || d > max {
+			max = d
+			worstIdx = i
+		}
+	}
+	mean = sum / float64(len(diffs))
+
+	// Nearest-rank percentile
+	slices.Sort(diffs)
+	rank := int(math.Ceil(0.95*float64(len(diffs)))) - 1
+	p95 = diffs[rank]
+
+	return mean, p95, max, worstIdx
+}
@@ -0,0 +1,68 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeltaEImage(t *testing.T) {
+	a := [][3]float64{
+		{0, 0, 0},
+		{1, 1, 1},
+		{0.2, 0.4, 0.6},
+		{1, 0, 0},
+	}
+	b := make([][3]float64, len(a))
+	copy(b, a)
+	out := make([]float64, len(a))
+
+	DeltaEImage(a, b, SRGB, DeltaEOK, out)
+	for i, d := range out {
+		if d != 0 {
+			t.Errorf("pixel %d: got difference %g for identical images, want 0", i, d)
+		}
+	}
+
+	b[2] = [3]float64{0.2, 0.5, 0.6}
+	DeltaEImage(a, b, SRGB, DeltaEOK, out)
+	for i, d := range out {
+		if i == 2 {
+			if d == 0 {
+				t.Errorf("pixel %d: got difference 0 for changed pixel", i)
+			}
+		} else if d != 0 {
+			t.Errorf("pixel %d: got difference %g for unchanged pixel, want 0", i, d)
+		}
+	}
+}
+
+func TestImageDiffStats(t *testing.T) {
+	a := make([][3]float64, 100)
+	b := make([][3]float64, 100)
+	for i := range a {
+		v := float64(i) / 100
+		a[i] = [3]float64{v, v, v}
+		b[i] = [3]float64{v, v, v}
+	}
+	b[42] = [3]float64{1, 0, 0}
+
+	mean, p95, max, worstIdx := ImageDiffStats(a, b, SRGB, DeltaEOK)
+	if worstIdx != 42 {
+		t.Errorf("got worst index %d, want 42", worstIdx)
+	}
+	ref := Make(SRGB, a[42][0], a[42][1], a[42][2], 1)
+	sample := Make(SRGB, 1, 0, 0, 1)
+	if want := DeltaEOK(&ref, &sample); max != want {
+		t.Errorf("got max %g, want %g", max, want)
+	}
+	if want := max / 100; math.Abs(mean-want) > 1e-12 {
+		t.Errorf("got mean %g, want %g", mean, want)
+	}
+	if p95 != 0 {
+		t.Errorf("got 95th percentile %g, want 0", p95)
+	}
+
+	if _, _, _, worstIdx := ImageDiffStats(nil, nil, SRGB, DeltaEOK); worstIdx != -1 {
+		t.Errorf("got worst index %d for empty image, want -1", worstIdx)
+	}
+}
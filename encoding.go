@@ -0,0 +1,206 @@
+package color
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// jsonColor is the JSON representation of a Color.
+type jsonColor struct {
+	Space  string        `json:"space"`
+	Values [3]jsonNumber `json:"values"`
+	Alpha  jsonNumber    `json:"alpha"`
+}
+
+// jsonNumber is a float64 that encodes NaN, which denotes a missing
+// component, as null.
+type jsonNumber float64
+
+func (n jsonNumber) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(n)) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(n))
+}
+
+func (n *jsonNumber) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*n = jsonNumber(math.NaN())
+		return nil
+	}
+	return json.Unmarshal(data, (*float64)(n))
+}
+
+// MarshalJSON implements [json.Marshaler]. Colors are encoded as objects of
+// the form {"space":"oklch","values":[0.5,0.1,120],"alpha":1}, identifying the
+// color space by its ID. Missing components and alpha are encoded as null.
+// The space should be registered (see [RegisterSpace]) for the color to be
+// decodable.
+func (c Color) MarshalJSON() ([]byte, error) {
+	if c.Space == nil {
+		return nil, errors.New("color has no color space")
+	}
+	return json.Marshal(jsonColor{
+		Space:  c.Space.ID,
+		Values: [3]jsonNumber{jsonNumber(c.Values[0]), jsonNumber(c.Values[1]), jsonNumber(c.Values[2])},
+		Alpha:  jsonNumber(c.Alpha),
+	})
+}
+
+// UnmarshalJSON implements [json.Unmarshaler], decoding the format produced by
+// [Color.MarshalJSON]. The color space is looked up with [LookupSpace]. A
+// missing alpha defaults to 1, and null values are decoded as missing (NaN).
+func (c *Color) UnmarshalJSON(data []byte) error {
+	jc := jsonColor{Alpha: 1}
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return err
+	}
+	cs, ok := LookupSpace(jc.Space)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownSpace, jc.Space)
+	}
+	*c = Color{
+		Values: [3]float64{float64(jc.Values[0]), float64(jc.Values[1]), float64(jc.Values[2])},
+		Space:  cs,
+		Alpha:  float64(jc.Alpha),
+	}
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Colors are encoded in the
+// CSS color() notation, like [Color.String], but with as many digits as
+// necessary to represent the values exactly.
+func (c Color) MarshalText() ([]byte, error) {
+	if c.Space == nil {
+		return nil, errors.New("color has no color space")
+	}
+	return []byte(c.FormatPrec(-1)), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts any color
+// that can be parsed by [Parse] and returns the error of [ParseErr] otherwise.
+func (c *Color) UnmarshalText(text []byte) error {
+	cc, err := ParseErr(string(text))
+	if err != nil {
+		return err
+	}
+	*c = cc
+	return nil
+}
+
+// jsonSpace is the JSON representation of a matrix-based Space.
+type jsonSpace struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	White    *Chromaticity     `json:"white,omitempty"`
+	Base     string            `json:"base"`
+	Coords   [3]jsonCoordinate `json:"coords"`
+	ToBase   [3][3]float64     `json:"toBase"`
+	FromBase [3][3]float64     `json:"fromBase"`
+}
+
+// jsonCoordinate is the JSON representation of a Coordinate. Because JSON
+// can't represent infinities, infinite bounds are encoded as null.
+type jsonCoordinate struct {
+	Name     string      `json:"name"`
+	Range    [2]*float64 `json:"range"`
+	RefRange [2]*float64 `json:"refRange"`
+	IsAngle  bool        `json:"isAngle,omitempty"`
+}
+
+func toJSONRange(r [2]float64) [2]*float64 {
+	var out [2]*float64
+	for i, v := range r {
+		if !math.IsInf(v, 0) {
+			out[i] = &v
+		}
+	}
+	return out
+}
+
+func fromJSONRange(r [2]*float64) [2]float64 {
+	out := infty
+	for i, v := range r {
+		if v != nil {
+			out[i] = *v
+		}
+	}
+	return out
+}
+
+// MarshalJSON implements [json.Marshaler] for color spaces that are converted
+// to and from their base space by plain matrix multiplications, such as the
+// linear RGB spaces and spaces returned by [NewXYZSpace]. The encoding
+// contains the space's ID, name, white point, the ID of its base space, its
+// coordinates, and the two matrices, and can be loaded with
+// [LoadColorSpace]. Spaces whose conversions are arbitrary functions, such as
+// [SRGB] with its transfer function, can't be encoded and cause an error.
+func (cs *Space) MarshalJSON() ([]byte, error) {
+	if cs.Base == nil || cs.toBaseMatrix == nil || cs.fromBaseMatrix == nil {
+		return nil, fmt.Errorf("color space %s isn't defined by matrices", cs.Name)
+	}
+	js := jsonSpace{
+		ID:       cs.ID,
+		Name:     cs.Name,
+		White:    cs.White,
+		Base:     cs.Base.ID,
+		ToBase:   *cs.toBaseMatrix,
+		FromBase: *cs.fromBaseMatrix,
+	}
+	for i, coord := range cs.Coords {
+		js.Coords[i] = jsonCoordinate{
+			Name:     coord.Name,
+			Range:    toJSONRange(coord.Range),
+			RefRange: toJSONRange(coord.RefRange),
+			IsAngle:  coord.IsAngle,
+		}
+	}
+	return json.Marshal(js)
+}
+
+// LoadColorSpace decodes a color space in the format produced by
+// [Space.MarshalJSON] and registers it with [RegisterSpaceErr]. The base space
+// is looked up with [LookupSpace] and must already be registered. It returns
+// an error if the data is malformed, the base space is unknown, or the ID is
+// already in use by a different space.
+func LoadColorSpace(data []byte) (*Space, error) {
+	var js jsonSpace
+	if err := json.Unmarshal(data, &js); err != nil {
+		return nil, err
+	}
+	if js.ID == "" {
+		return nil, errors.New("color space has no ID")
+	}
+	base, ok := LookupSpace(js.Base)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSpace, js.Base)
+	}
+	toBase, fromBase := js.ToBase, js.FromBase
+	cs := &Space{
+		ID:    js.ID,
+		Name:  js.Name,
+		White: js.White,
+		Base:  base,
+		ToBase: func(c *[3]float64) [3]float64 {
+			return MulVecMat(c, &toBase)
+		},
+		FromBase: func(c *[3]float64) [3]float64 {
+			return MulVecMat(c, &fromBase)
+		},
+	}
+	for i, coord := range js.Coords {
+		cs.Coords[i] = Coordinate{
+			Name:     coord.Name,
+			Range:    fromJSONRange(coord.Range),
+			RefRange: fromJSONRange(coord.RefRange),
+			IsAngle:  coord.IsAngle,
+		}
+	}
+	cs.initMatrices(&toBase, &fromBase)
+	if err := RegisterSpaceErr(cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
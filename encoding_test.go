@@ -0,0 +1,161 @@
+package color
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	c := Make(Oklch, 0.5, 0.1, 120, 0.25)
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"space":"oklch","values":[0.5,0.1,120],"alpha":0.25}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var got Color
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != c {
+		t.Errorf("got %v, want %v", got, c)
+	}
+
+	if err := json.Unmarshal([]byte(`{"space":"srgb","values":[1,0,0]}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := Make(SRGB, 1, 0, 0, 1); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	err = json.Unmarshal([]byte(`{"space":"nonexistent","values":[1,0,0]}`), &got)
+	if err == nil {
+		t.Errorf("expected error for unknown color space")
+	}
+}
+
+func TestJSONMissing(t *testing.T) {
+	nan := math.NaN()
+	c := Make(Oklch, 0.5, 0.1, nan, nan)
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"space":"oklch","values":[0.5,0.1,null],"alpha":null}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var got Color
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Space != c.Space || got.Values[0] != 0.5 || got.Values[1] != 0.1 ||
+		!math.IsNaN(got.Values[2]) || !math.IsNaN(got.Alpha) {
+		t.Errorf("got %v, want %v", got, c)
+	}
+}
+
+func TestText(t *testing.T) {
+	var (
+		_ encoding.TextMarshaler   = Color{}
+		_ encoding.TextUnmarshaler = (*Color)(nil)
+	)
+
+	for _, c := range []Color{
+		Make(SRGB, 0.1, 0.2, 0.3, 1),
+		Make(Oklch, 0.627955, 0.257683, 29.2338851923426, 0.5),
+		Make(XyY, 0.3127, 0.329, 1, 1),
+	} {
+		text, err := c.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Color
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != c {
+			t.Errorf("%s: got %v, want %v", text, got, c)
+		}
+	}
+
+	var c Color
+	if err := c.UnmarshalText([]byte("color(srgb 1 0)")); err == nil {
+		t.Errorf("expected error for invalid color")
+	}
+	if err := c.UnmarshalText([]byte("#ff8000")); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestSpaceJSON(t *testing.T) {
+	data, err := json.Marshal(LinearDisplayP3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadColorSpace(data); err == nil {
+		t.Errorf("expected error for ID already in use")
+	}
+
+	data = bytes.Replace(data, []byte(`"id":"display-p3-linear"`), []byte(`"id":"test-load-p3"`), 1)
+	cs, err := LoadColorSpace(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterSpace(cs.ID)
+	if got, ok := LookupSpace("test-load-p3"); !ok || got != cs {
+		t.Errorf("loaded space wasn't registered")
+	}
+	if cs.Name != LinearDisplayP3.Name || *cs.White != *LinearDisplayP3.White ||
+		cs.Base != LinearDisplayP3.Base || cs.Coords != LinearDisplayP3.Coords {
+		t.Errorf("got %+v, want %+v", cs, LinearDisplayP3)
+	}
+	for _, in := range [][3]float64{{0, 0, 0}, {1, 1, 1}, {0.2, 0.5, 0.8}, {0.9, 0.1, 0.3}} {
+		for _, to := range []*Space{SRGB, Oklab, Lab} {
+			if got, want := cs.Convert(to, in), LinearDisplayP3.Convert(to, in); got != want {
+				t.Errorf("%v -> %s: got %v, want %v", in, to.ID, got, want)
+			}
+		}
+	}
+	if _, ok := RGBToRGBMatrix(cs, LinearSRGB); !ok {
+		t.Errorf("loaded space isn't treated as a matrix space")
+	}
+
+	again, err := json.Marshal(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("got %s, want %s", again, data)
+	}
+
+	// Infinite ranges survive the round trip.
+	d75 := NewXYZSpace("XYZ D75", "test-load-xyz-d75", &Chromaticity{0.29902, 0.31485})
+	data, err = json.Marshal(d75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err = LoadColorSpace(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterSpace(cs.ID)
+	if cs.Coords != d75.Coords {
+		t.Errorf("got %v, want %v", cs.Coords, d75.Coords)
+	}
+
+	for _, cs := range []*Space{SRGB, Oklab, XYZ_D65} {
+		if _, err := json.Marshal(cs); err == nil {
+			t.Errorf("%s: expected error", cs.ID)
+		}
+	}
+	if _, err := LoadColorSpace([]byte(`{"id":"test-load-bad","base":"no-such-space"}`)); !errors.Is(err, ErrUnknownSpace) {
+		t.Errorf("got %v, want %v", err, ErrUnknownSpace)
+	}
+}
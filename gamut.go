@@ -0,0 +1,250 @@
+package color
+
+import (
+	"fmt"
+	"iter"
+	"math"
+)
+
+// SampleGamut yields the in-gamut colors on a regular grid over the reference
+// ranges (see [Coordinate]) of the color space's coordinates. step is the
+// distance between grid points as a fraction of each reference range and must
+// be in (0, 1]. For example, a step of 0.25 produces 5 grid points per
+// coordinate. For angle coordinates, the upper end of the range is skipped, as
+// it is identical to the lower end.
+//
+// Colors are produced lazily, so sampling fine grids doesn't require
+// proportional amounts of memory.
+func SampleGamut(space *Space, step float64) iter.Seq[Color] {
+	if !(step > 0 && step <= 1) {
+		panic("step must be in (0, 1]")
+	}
+	// Allow for a small amount of floating point error so that steps like 0.1
+	// include the end of the range.
+	n := int(math.Floor(1/step+1e-9)) + 1
+	var counts [3]int
+	for i, coord := range space.Coords {
+		counts[i] = n
+		if coord.IsAngle && float64(n-1)*step >= 1-1e-9 {
+			counts[i]--
+		}
+	}
+	value := func(coord, i int) float64 {
+		rng := space.Coords[coord].RefRange
+		return lerp(rng[0], rng[1], min(float64(i)*step, 1))
+	}
+
+	return func(yield func(Color) bool) {
+		for i := range counts[0] {
+			for j := range counts[1] {
+				for k := range counts[2] {
+					c := Make(space, value(0, i), value(1, j), value(2, k), 1)
+					if !c.InGamut() {
+						continue
+					}
+					if !yield(c) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// ClosestInGamut maps c to the destination color space by searching for the
+// in-gamut color that minimizes the color difference computed by delta, such
+// as [DeltaEOK] or [DeltaE76]. Colors that are already in gamut are only
+// converted.
+//
+// Unlike [GamutMapCSS], which only reduces chroma in Oklch and always measures
+// differences with [DeltaEOK], ClosestInGamut may change lightness and hue,
+// too. The search is a local optimization starting at the clipped color (see
+// [GamutClip]) and isn't guaranteed to find the global minimum, but its result
+// is never worse than clipping.
+func ClosestInGamut(c *Color, to *Space, delta DeltaEFunc) Color {
+	if cc := c.Convert(to); cc.InGamut() {
+		return cc
+	}
+
+	// We search in Oklab, evaluating each point by its clipped version. This
+	// allows the search to move freely while always producing in-gamut
+	// candidates.
+	eval := func(p [3]float64) (Color, float64) {
+		cand := Make(Oklab, p[0], p[1], p[2], c.Alpha)
+		clipped := GamutClip(&cand, to)
+		return clipped, delta(c, &clipped)
+	}
+
+	bestColor := GamutClip(c, to)
+	bestDelta := delta(c, &bestColor)
+	best := bestColor.Convert(Oklab).Values
+	for step := 0.05; step > 1e-6; step /= 2 {
+		for improved := true; improved; {
+			improved = false
+			for i := range 3 {
+				for _, dir := range [2]float64{-1, 1} {
+					p := best
+					p[i] += dir * step
+					if cand, d := eval(p); d < bestDelta {
+						best, bestColor, bestDelta = p, cand, d
+						improved = true
+					}
+				}
+			}
+		}
+	}
+	return bestColor
+}
+
+// GamutVolume estimates the volume of the gamut of cs when measured in the
+// color space in, such as [Oklab] or [Lab]. The gamut of cs is the box spanned
+// by the ranges of its coordinates, such as the unit cube for RGB spaces; cs
+// must not have angle coordinates or unbounded ranges. in should be a
+// Cartesian space, as volumes in polar spaces aren't meaningful.
+//
+// The surface of the gamut is divided into an n×n grid of squares per face,
+// each split into two triangles that are converted to in, and the volume
+// enclosed by the resulting triangle mesh is computed exactly. Larger values of
+// n approximate the curved surface more closely, at a cost of O(n²)
+// conversions. For RGB spaces measured in Oklab, n = 32 is accurate to better
+// than 0.1%.
+func GamutVolume(cs, in *Space, n int) float64 {
+	if n < 1 {
+		panic("n must be at least 1")
+	}
+	checkBoxGamut(cs)
+
+	// By the divergence theorem, the volume enclosed by a closed mesh is the
+	// sum of the signed volumes of the tetrahedra formed by the origin and each
+	// consistently oriented triangle.
+	var vol float64
+	for tri := range gamutSurface(cs, n) {
+		var p [3][3]float64
+		for i, v := range tri {
+			p[i] = cs.Convert(in, v)
+		}
+		vol += p[0][0]*(p[1][1]*p[2][2]-p[1][2]*p[2][1]) -
+			p[0][1]*(p[1][0]*p[2][2]-p[1][2]*p[2][0]) +
+			p[0][2]*(p[1][0]*p[2][1]-p[1][1]*p[2][0])
+	}
+	// The orientation of the mesh depends on the handedness of the conversion,
+	// so we don't know the sign of the volume.
+	return math.Abs(vol) / 6
+}
+
+// GamutContains reports whether the gamut of outer contains the gamut of inner,
+// by checking that points on the surface of inner's gamut are in the gamut of
+// outer, allowing for an error of up to ϵ (see [Space.InGamutTol]). The gamut of
+// inner is the box spanned by the ranges of its coordinates, such as the unit
+// cube for RGB spaces; inner must not have angle coordinates or unbounded
+// ranges.
+//
+// Each face of inner's gamut is sampled on a grid with samples+1 points per
+// side. The result is only definitive for the sampled points, but because
+// gamuts are smooth, moderate values such as 16 suffice in practice.
+func GamutContains(outer, inner *Space, samples int, ϵ float64) bool {
+	if samples < 1 {
+		panic("samples must be at least 1")
+	}
+	checkBoxGamut(inner)
+	cv := NewConverter(inner, outer)
+	for tri := range gamutSurface(inner, samples) {
+		for _, p := range tri {
+			if !outer.InGamutTol(cv.Convert(p), ϵ) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkBoxGamut panics if the gamut of cs isn't a box.
+func checkBoxGamut(cs *Space) {
+	if cs.IsPolar() {
+		panic(fmt.Sprintf("color space %s is polar", cs.Name))
+	}
+	for _, coord := range cs.Coords {
+		if math.IsInf(coord.Range[0], 0) || math.IsInf(coord.Range[1], 0) {
+			panic(fmt.Sprintf("color space %s has an unbounded gamut", cs.Name))
+		}
+	}
+}
+
+// gamutSurface yields consistently oriented triangles covering the surface of
+// the box spanned by the ranges of cs's coordinates. Each face is divided into
+// an n×n grid of squares, each consisting of two triangles.
+func gamutSurface(cs *Space, n int) iter.Seq[[3][3]float64] {
+	return func(yield func([3][3]float64) bool) {
+		for axis := range 3 {
+			u := (axis + 1) % 3
+			v := (axis + 2) % 3
+			for side := range 2 {
+				point := func(i, j int) [3]float64 {
+					var p [3]float64
+					p[axis] = cs.Coords[axis].Range[side]
+					p[u] = lerp(cs.Coords[u].Range[0], cs.Coords[u].Range[1], float64(i)/float64(n))
+					p[v] = lerp(cs.Coords[v].Range[0], cs.Coords[v].Range[1], float64(j)/float64(n))
+					return p
+				}
+				for i := range n {
+					for j := range n {
+						p00 := point(i, j)
+						p10 := point(i+1, j)
+						p01 := point(i, j+1)
+						p11 := point(i+1, j+1)
+						// (u, v, axis) is a right-handed frame, so
+						// counterclockwise triangles in the uv plane face
+						// towards increasing axis values. Flip them on the
+						// lower side, so that all of them face outwards.
+						t1 := [3][3]float64{p00, p10, p11}
+						t2 := [3][3]float64{p00, p11, p01}
+						if side == 0 {
+							t1[1], t1[2] = t1[2], t1[1]
+							t2[1], t2[2] = t2[2], t2[1]
+						}
+						if !yield(t1) || !yield(t2) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// GamutMapLCh maps c to the destination color space by reducing its chroma in
+// [LCh], keeping lightness and hue fixed, until it is in gamut, and then
+// clipping any remaining error. This is the gamut mapping algorithm specified by
+// earlier drafts of CSS Color Module Level 4 and is provided for compatibility.
+// [GamutMapCSS] produces better results.
+//
+// Colors that are already in gamut are only converted. Colors with a lightness
+// of 100 or more map to white, and colors with a lightness of 0 or less map to
+// black.
+func GamutMapLCh(c *Color, to *Space) Color {
+	if cc := c.Convert(to); cc.InGamut() {
+		return cc
+	}
+	lch := c.Convert(LCh)
+	if lch.Values[0] >= 100 {
+		white := Make(Lab, 100, 0, 0, c.Alpha)
+		return white.Convert(to)
+	}
+	if lch.Values[0] <= 0 {
+		black := Make(Lab, 0, 0, 0, c.Alpha)
+		return black.Convert(to)
+	}
+
+	const ϵ = 0.001
+	lo, hi := 0.0, lch.Values[1]
+	for hi-lo > ϵ {
+		lch.Values[1] = (lo + hi) / 2
+		if lch.InGamutOf(to) {
+			lo = lch.Values[1]
+		} else {
+			hi = lch.Values[1]
+		}
+	}
+	lch.Values[1] = lo
+	return GamutClip(&lch, to)
+}
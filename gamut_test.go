@@ -0,0 +1,313 @@
+package color
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSampleGamut(t *testing.T) {
+	tests := []struct {
+		space *Space
+		step  float64
+		want  int
+	}{
+		{SRGB, 0.25, 5 * 5 * 5},
+		{DisplayP3, 0.1, 11 * 11 * 11},
+		// Hue wraps around, so 360° isn't sampled separately from 0°.
+		{Oklch, 0.5, 3 * 3 * 2},
+	}
+	for _, tt := range tests {
+		n := 0
+		for c := range SampleGamut(tt.space, tt.step) {
+			n++
+			if !c.InGamut() {
+				t.Errorf("%s: got out of gamut color %v", tt.space.Name, c)
+			}
+		}
+		if n != tt.want {
+			t.Errorf("%s: got %d colors, want %d", tt.space.Name, n, tt.want)
+		}
+	}
+
+	// A space with a restricted gamut on one axis.
+	restricted := (&Space{
+		ID:     "test-restricted",
+		Name:   "Restricted",
+		Base:   LinearSRGB,
+		Coords: RGBCoordinates,
+		FromBase: func(c *[3]float64) [3]float64 {
+			return *c
+		},
+		ToBase: func(c *[3]float64) [3]float64 {
+			return *c
+		},
+	}).Init()
+	restricted.Coords[0].RefRange = [2]float64{-1, 1}
+	n := 0
+	for range SampleGamut(restricted, 0.5) {
+		n++
+	}
+	// Of the red values -1, 0, and 1, only 0 and 1 are in gamut.
+	if want := 2 * 3 * 3; n != want {
+		t.Errorf("got %d colors, want %d", n, want)
+	}
+}
+
+func TestInGamutMissing(t *testing.T) {
+	c := Make(Oklch, 0.5, 0.2, math.NaN(), 1)
+	if !c.InGamut() {
+		t.Errorf("%v should be in gamut of its own space", c)
+	}
+	if !c.InGamutOf(Oklch) {
+		t.Errorf("%v should be in gamut of %s", c, Oklch.Name)
+	}
+	// With a hue of 0, the color is in gamut of sRGB.
+	if !c.InGamutOf(SRGB) {
+		t.Errorf("%v should be in gamut of %s", c, SRGB.Name)
+	}
+
+	c = Make(SRGB, math.NaN(), 0.5, 1.5, 1)
+	if c.InGamut() {
+		t.Errorf("%v shouldn't be in gamut", c)
+	}
+}
+
+func TestGamutClip(t *testing.T) {
+	red := Make(DisplayP3, 1, 0, 0, 1)
+	if red.InGamutOf(SRGB) {
+		t.Fatal("Display P3 red shouldn't be in gamut of sRGB")
+	}
+	got := GamutClip(&red, SRGB)
+	if got.Space != SRGB {
+		t.Errorf("got space %s, want %s", got.Space.Name, SRGB.Name)
+	}
+	for i, v := range got.Values {
+		if v < 0 || v > 1 {
+			t.Errorf("coordinate %d: got %g, want value in [0, 1]", i, v)
+		}
+	}
+	if !got.InGamut() {
+		t.Errorf("got out of gamut color %v", got)
+	}
+
+	in := Make(DisplayP3, 0.5, 0.4, 0.3, 1)
+	want := in.Convert(SRGB)
+	if got := GamutClip(&in, SRGB); got != want {
+		t.Errorf("got %v, want unchanged %v", got, want)
+	}
+}
+
+func gamutMapTestColors() []Color {
+	var colors []Color
+	for c := range SampleGamut(Oklch, 0.1) {
+		colors = append(colors, c)
+	}
+	return colors
+}
+
+func TestGamutMapCSSBatch(t *testing.T) {
+	colors := gamutMapTestColors()
+	for _, to := range []*Space{SRGB, DisplayP3, Oklab} {
+		dst := make([]Color, len(colors))
+		GamutMapCSSBatch(colors, to, dst)
+		for i := range colors {
+			if want := GamutMapCSS(&colors[i], to); dst[i] != want {
+				t.Fatalf("%v: got %v, want %v", colors[i], dst[i], want)
+			}
+		}
+
+		inPlace := slices.Clone(colors)
+		GamutMapCSSBatch(inPlace, to, inPlace)
+		if !slices.Equal(inPlace, dst) {
+			t.Errorf("mapping in place produced different results")
+		}
+	}
+}
+
+func BenchmarkGamutMapCSS(b *testing.B) {
+	colors := gamutMapTestColors()
+	dst := make([]Color, len(colors))
+
+	b.Run("loop", func(b *testing.B) {
+		for range b.N {
+			for i := range colors {
+				dst[i] = GamutMapCSS(&colors[i], SRGB)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for range b.N {
+			GamutMapCSSBatch(colors, SRGB, dst)
+		}
+	})
+}
+
+func TestClosestInGamut(t *testing.T) {
+	in := Make(DisplayP3, 0.5, 0.4, 0.3, 1)
+	if got, want := ClosestInGamut(&in, SRGB, DeltaEOK), in.Convert(SRGB); got != want {
+		t.Errorf("got %v, want unchanged %v", got, want)
+	}
+
+	for _, c := range []Color{
+		Make(DisplayP3, 1, 0, 0, 1),
+		Make(DisplayP3, 0, 1, 0, 0.5),
+		Make(Oklch, 0.65, 0.29, 0, 1),
+	} {
+		for _, delta := range []DeltaEFunc{DeltaEOK, DeltaE76} {
+			got := ClosestInGamut(&c, SRGB, delta)
+			if got.Space != SRGB || !got.InGamut() {
+				t.Errorf("%v: got out of gamut color %v", c, got)
+			}
+			if got.Alpha != c.Alpha {
+				t.Errorf("%v: got alpha %g, want %g", c, got.Alpha, c.Alpha)
+			}
+			clipped := GamutClip(&c, SRGB)
+			if d, dClipped := delta(&c, &got), delta(&c, &clipped); d > dClipped {
+				t.Errorf("%v: got difference %g, which is worse than clipping (%g)", c, d, dClipped)
+			}
+		}
+	}
+}
+
+func TestGamutVolume(t *testing.T) {
+	if got := GamutVolume(SRGB, SRGB, 4); math.Abs(got-1) > 1e-12 {
+		t.Errorf("volume of sRGB in sRGB: got %g, want 1", got)
+	}
+
+	// Linear transformations scale volumes by their determinant.
+	m := [3][3]float64{
+		{0.41239079926595934, 0.357584339383878, 0.1804807884018343},
+		{0.21263900587151027, 0.715168678767756, 0.07219231536073371},
+		{0.01933081871559182, 0.11919477979462598, 0.9505321522496607},
+	}
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if got := GamutVolume(LinearSRGB, XYZ_D65, 1); math.Abs(got-det) > 1e-9 {
+		t.Errorf("volume of linear sRGB in XYZ: got %g, want %g", got, det)
+	}
+
+	srgb := GamutVolume(SRGB, Oklab, 32)
+	p3 := GamutVolume(DisplayP3, Oklab, 32)
+	if p3 <= srgb {
+		t.Errorf("Display P3 (%g) isn't larger than sRGB (%g)", p3, srgb)
+	}
+	// Coarser sampling should be close.
+	if coarse := GamutVolume(SRGB, Oklab, 16); math.Abs(coarse-srgb)/srgb > 0.01 {
+		t.Errorf("coarse volume %g differs from %g by more than 1%%", coarse, srgb)
+	}
+}
+
+func TestGamutContains(t *testing.T) {
+	const ϵ = 0.000075
+	tests := []struct {
+		outer, inner *Space
+		want         bool
+	}{
+		{DisplayP3, SRGB, true},
+		{SRGB, DisplayP3, false},
+		{Rec2020, SRGB, true},
+		{ProPhoto, SRGB, true},
+		{SRGB, SRGB, true},
+		{Oklab, Rec2020, true},
+		// Display P3's red primary lies just outside of Rec. 2020's gamut.
+		{Rec2020, DisplayP3, false},
+	}
+	for _, tt := range tests {
+		if got := GamutContains(tt.outer, tt.inner, 16, ϵ); got != tt.want {
+			t.Errorf("%s contains %s: got %t, want %t", tt.outer.ID, tt.inner.ID, got, tt.want)
+		}
+	}
+}
+
+func TestGamutMapCSSOpts(t *testing.T) {
+	defaults := GamutMapOptions{DeltaE: DeltaEOK, JND: 0.02, Epsilon: 0.0001}
+	for _, c := range gamutMapTestColors() {
+		want := GamutMapCSS(&c, SRGB)
+		if got := GamutMapCSSOpts(&c, SRGB, defaults); got != want {
+			t.Errorf("explicit defaults: got %v, want %v", got, want)
+		}
+		if got := GamutMapCSSOpts(&c, SRGB, GamutMapOptions{}); got != want {
+			t.Errorf("zero options: got %v, want %v", got, want)
+		}
+	}
+
+	// With a large JND, clipping is always acceptable.
+	red := Make(DisplayP3, 1, 0, 0, 1)
+	def := GamutMapCSS(&red, SRGB)
+	got := GamutMapCSSOpts(&red, SRGB, GamutMapOptions{JND: 0.2})
+	lch := red.Convert(Oklch)
+	if want := GamutClip(&lch, SRGB); got != want {
+		t.Errorf("got %v, want clipped color %v", got, want)
+	}
+	if got == def {
+		t.Errorf("larger JND didn't change the result")
+	}
+	if !got.InGamut() {
+		t.Errorf("%v isn't in gamut", got)
+	}
+
+	if got := GamutMapCSSOpts(&red, SRGB, GamutMapOptions{DeltaE: DeltaEOK2}); !got.InGamut() {
+		t.Errorf("%v isn't in gamut", got)
+	}
+}
+
+func TestGamutMapLCh(t *testing.T) {
+	for _, c := range []Color{
+		Make(DisplayP3, 1, 0, 0, 1),
+		Make(DisplayP3, 0, 1, 0, 1),
+		Make(Rec2020, 0.2, 0.3, 1, 0.5),
+		Make(LCh, 60, 120, 300, 1),
+	} {
+		got := GamutMapLCh(&c, SRGB)
+		if got.Space != SRGB || !got.InGamut() {
+			t.Errorf("%v: got %v, which isn't in gamut of sRGB", c, got)
+		}
+		if got.Alpha != c.Alpha {
+			t.Errorf("%v: got alpha %g, want %g", c, got.Alpha, c.Alpha)
+		}
+		want := c.Convert(LCh)
+		gotLCh := got.Convert(LCh)
+		if math.Abs(gotLCh.Values[0]-want.Values[0]) > 0.01 {
+			t.Errorf("%v: lightness changed from %g to %g", c, want.Values[0], gotLCh.Values[0])
+		}
+		if d := hueDistance(gotLCh.Values[2], want.Values[2]); d > 0.1 {
+			t.Errorf("%v: hue changed by %g°", c, d)
+		}
+		if gotLCh.Values[1] >= want.Values[1] {
+			t.Errorf("%v: chroma didn't decrease", c)
+		}
+	}
+
+	in := Make(SRGB, 0.2, 0.4, 0.6, 1)
+	if got := GamutMapLCh(&in, SRGB); got != in {
+		t.Errorf("in-gamut color changed from %v to %v", in, got)
+	}
+}
+
+func TestGamutMapCSSReport(t *testing.T) {
+	for _, c := range []Color{
+		Make(SRGB, 0.2, 0.4, 0.6, 1),
+		Make(SRGB, 1, 1, 1, 1),
+		Make(SRGB, 0, 0, 0, 1),
+		Make(DisplayP3, 0.5, 0.5, 0.5, 1),
+	} {
+		got, changed := GamutMapCSSReport(&c, SRGB)
+		if changed {
+			t.Errorf("%v: reported as changed", c)
+		}
+		if want := GamutMapCSS(&c, SRGB); got != want {
+			t.Errorf("%v: got %v, want %v", c, got, want)
+		}
+	}
+
+	red := Make(DisplayP3, 1, 0, 0, 1)
+	got, changed := GamutMapCSSReport(&red, SRGB)
+	if !changed {
+		t.Errorf("%v: not reported as changed", red)
+	}
+	if want := GamutMapCSS(&red, SRGB); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package color
+
+import "math"
+
+func init() {
+	RegisterSpace(HCT)
+}
+
+// hctViewingConditions are the viewing conditions used by HCT: a D65 white
+// point, a background of L* = 50, and an average surround.
+var hctViewingConditions = (&CAM16Environment{
+	White:               WhitesSRGBD65,
+	AdaptingLuminance:   200 / math.Pi * lstarToY(50),
+	BackgroundLuminance: lstarToY(50),
+	Surround:            2,
+}).viewingConditions()
+
+// HCT is Google's HCT color space, used by Material Design. It combines the
+// hue and chroma of the CAM16 color appearance model with the tone, or
+// lightness, L* of CIELAB. Converting from HCT to XYZ requires an iterative
+// search and is thus slower than for most other color spaces.
+var HCT = (&Space{
+	ID:   "hct",
+	Name: "HCT",
+	Coords: [3]Coordinate{
+		{Name: "Hue", Range: infty, IsAngle: true, RefRange: [2]float64{0, 360}},
+		{Name: "Chroma", Range: infty, RefRange: [2]float64{0, 145}},
+		{Name: "Tone", Range: infty, RefRange: [2]float64{0, 100}},
+	},
+	Base: XYZ_D65,
+	FromBase: func(c *[3]float64) [3]float64 {
+		t := yToLstar(c[1])
+		if t == 0 {
+			return [3]float64{}
+		}
+		cam := xyzToCAM16(*c, hctViewingConditions)
+		return [3]float64{cam.h, cam.C, t}
+	},
+	ToBase: func(c *[3]float64) [3]float64 {
+		h, chroma, t := c[0], c[1], c[2]
+		if t == 0 {
+			return [3]float64{}
+		}
+		y := lstarToY(t)
+
+		// Initial estimate of CAM16 J, followed by Newton iterations on Y.
+		var j float64
+		if t > 0 {
+			j = 0.00379058511492914*t*t + 0.608983189401032*t + 0.9155088574762233
+		} else {
+			j = 9.514440756550361e-6*t*t + 0.08693057439788597*t - 21.928975842194614
+		}
+		const (
+			threshold   = 2e-12
+			maxAttempts = 15
+		)
+		last := math.Inf(1)
+		best := j
+		for range maxAttempts {
+			xyz := cam16ToXYZ(j, chroma, h, hctViewingConditions)
+			delta := math.Abs(xyz[1] - y)
+			if delta < last {
+				if delta <= threshold {
+					return xyz
+				}
+				best = j
+				last = delta
+			}
+			if xyz[1] == 0 {
+				break
+			}
+			j -= (xyz[1] - y) * j / (2 * xyz[1])
+		}
+		return cam16ToXYZ(best, chroma, h, hctViewingConditions)
+	},
+}).Init()
+
+// yToLstar returns the CIELAB lightness L* of the relative luminance y.
+func yToLstar(y float64) float64 {
+	const (
+		ϵ = 216.0 / 24389.0
+		κ = 24389.0 / 27.0
+	)
+	if y > ϵ {
+		return 116*math.Cbrt(y) - 16
+	}
+	return κ * y
+}
+
+// lstarToY returns the relative luminance of the CIELAB lightness L*.
+func lstarToY(l float64) float64 {
+	const κ = 24389.0 / 27.0
+	if l > 8 {
+		n := (l + 16) / 116
+		return n * n * n
+	}
+	return l / κ
+}
@@ -0,0 +1,43 @@
+package color
+
+import "testing"
+
+func TestHCT(t *testing.T) {
+	// Reference values from Material Color Utilities.
+	tests := []struct {
+		in   Color
+		want [3]float64
+	}{
+		{Make(SRGB, 1, 0, 0, 1), [3]float64{27.4098, 113.3564, 53.2371}},
+		{Make(SRGB, 0, 1, 0, 1), [3]float64{142.1404, 108.4065, 87.7355}},
+		{Make(SRGB, 0, 0, 1, 1), [3]float64{282.7622, 87.2280, 32.3009}},
+		{Make(SRGB, 1, 1, 1, 1), [3]float64{209.5429, 2.8716, 100}},
+		{Make(SRGB, 0, 0, 0, 1), [3]float64{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		got := tt.in.Convert(HCT)
+		if !approxValues(got.Values, tt.want, 1e-3) {
+			t.Errorf("%v: got %v, want %v", tt.in, got.Values, tt.want)
+		}
+	}
+
+	for _, c := range []Color{
+		Make(SRGB, 0.4, 0.3, 0.8, 1),
+		Make(SRGB, 0.9, 0.9, 0.1, 1),
+		Make(DisplayP3, 0.1, 0.6, 0.5, 1),
+		Make(SRGB, 0.01, 0.02, 0.01, 1),
+	} {
+		hct := c.Convert(HCT)
+		back := hct.Convert(c.Space)
+		if !approxValues(back.Values, c.Values, 1e-9) {
+			t.Errorf("%v: got %v after round trip through HCT", c, back)
+		}
+	}
+
+	if cs, ok := LookupSpace("hct"); !ok || cs != HCT {
+		t.Errorf("HCT isn't registered")
+	}
+	if got := HCT.ToBase(&[3]float64{120, 30, 0}); got != ([3]float64{}) {
+		t.Errorf("got %v for tone 0, want black", got)
+	}
+}
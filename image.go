@@ -0,0 +1,120 @@
+package color
+
+import (
+	stdcolor "image/color"
+	"math"
+)
+
+// to8bit clamps f to [0, 1] and scales it to [0, 255], rounding to the
+// nearest integer.
+func to8bit(f float64) uint8 {
+	return uint8(math.Round(min(max(f, 0), 1) * 255))
+}
+
+// RGBA255 converts c to sRGB and returns its channels and alpha as 8-bit
+// values. Out-of-gamut colors are clipped to the sRGB gamut.
+func (c Color) RGBA255() (r, g, b, a uint8) {
+	cc := c.Convert(SRGB)
+	return to8bit(cc.Values[0]), to8bit(cc.Values[1]), to8bit(cc.Values[2]), to8bit(c.Alpha)
+}
+
+// NRGBA converts c to sRGB and returns it as a non-premultiplied 8-bit color
+// from the image/color package. Out-of-gamut colors are clipped to the sRGB
+// gamut.
+func (c Color) NRGBA() stdcolor.NRGBA {
+	r, g, b, a := c.RGBA255()
+	return stdcolor.NRGBA{R: r, G: g, B: b, A: a}
+}
+
+// FromNRGBA returns the sRGB color described by the non-premultiplied 8-bit
+// color c.
+func FromNRGBA(c stdcolor.NRGBA) Color {
+	return Make(SRGB,
+		float64(c.R)/255,
+		float64(c.G)/255,
+		float64(c.B)/255,
+		float64(c.A)/255,
+	)
+}
+
+// FromRGBA returns the sRGB color described by the alpha-premultiplied 8-bit
+// color c. Fully transparent colors are returned as transparent black.
+func FromRGBA(c stdcolor.RGBA) Color {
+	if c.A == 0 {
+		return Make(SRGB, 0, 0, 0, 0)
+	}
+	a := float64(c.A)
+	return Make(SRGB,
+		float64(c.R)/a,
+		float64(c.G)/a,
+		float64(c.B)/a,
+		a/255,
+	)
+}
+
+// ToRGBA converts c to sRGB and returns it as an alpha-premultiplied 8-bit
+// color from the image/color package. Out-of-gamut colors are clipped to the
+// sRGB gamut.
+func (c Color) ToRGBA() stdcolor.RGBA {
+	cc := c.Convert(SRGB)
+	a := min(max(c.Alpha, 0), 1)
+	return stdcolor.RGBA{
+		R: to8bit(cc.Values[0] * a),
+		G: to8bit(cc.Values[1] * a),
+		B: to8bit(cc.Values[2] * a),
+		A: to8bit(a),
+	}
+}
+
+// ConvertImageRGBA converts a buffer of alpha-premultiplied 8-bit pixels,
+// whose channels are coordinates in the space from, to colors in the space to,
+// storing the results in dst. It is equivalent to, but considerably faster
+// than, converting each pixel individually with [FromRGBA] and
+// [Color.Convert]. Fully transparent pixels are converted as transparent
+// black.
+//
+// ConvertImageRGBA panics if dst is shorter than src.
+func ConvertImageRGBA(src []stdcolor.RGBA, from, to *Space, dst []Color) {
+	if len(dst) < len(src) {
+		panic("destination is too small")
+	}
+	cv := NewConverter(from, to)
+	for i, p := range src {
+		var v [3]float64
+		var alpha float64
+		if p.A != 0 {
+			a := float64(p.A)
+			v = [3]float64{float64(p.R) / a, float64(p.G) / a, float64(p.B) / a}
+			alpha = a / 255
+		}
+		dst[i] = Color{Values: cv.Convert(v), Space: to, Alpha: alpha}
+	}
+}
+
+// ConvertColorsToRGBA is the inverse of [ConvertImageRGBA]. It converts src to
+// the space to and stores the colors as alpha-premultiplied 8-bit pixels in
+// dst, clipping coordinates to [0, 1]. Missing components are treated as
+// zero. The colors in src don't all have to be in the same space, but
+// conversion is fastest when consecutive colors are.
+//
+// ConvertColorsToRGBA panics if dst is shorter than src.
+func ConvertColorsToRGBA(src []Color, to *Space, dst []stdcolor.RGBA) {
+	if len(dst) < len(src) {
+		panic("destination is too small")
+	}
+	var cv *Converter
+	for i := range src {
+		c := &src[i]
+		if cv == nil || cv.From() != c.Space {
+			cv = NewConverter(c.Space, to)
+		}
+		v := cv.Convert(resolveMissing(c.Values))
+		a := min(max(c.Alpha, 0), 1)
+		dst[i] = stdcolor.RGBA{
+			R: to8bit(v[0] * a),
+			G: to8bit(v[1] * a),
+			B: to8bit(v[2] * a),
+			A: to8bit(a),
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package color
+
+import (
+	stdcolor "image/color"
+	"testing"
+)
+
+func TestRGBA255(t *testing.T) {
+	tests := []struct {
+		in         Color
+		r, g, b, a uint8
+	}{
+		{Make(SRGB, 1, 0, 0, 1), 255, 0, 0, 255},
+		// Values right below and at the rounding boundary
+		{Make(SRGB, 0.49/255, 0.5/255, 1.49/255, 0.5), 0, 1, 1, 128},
+		// Out of gamut values get clamped
+		{Make(SRGB, -0.5, 1.5, 0.5, 1), 0, 255, 128, 255},
+		{Make(DisplayP3, 0, 1, 0, 1), 0, 255, 0, 255},
+	}
+	for _, tt := range tests {
+		r, g, b, a := tt.in.RGBA255()
+		if r != tt.r || g != tt.g || b != tt.b || a != tt.a {
+			t.Errorf("%v: got (%d, %d, %d, %d), want (%d, %d, %d, %d)",
+				tt.in, r, g, b, a, tt.r, tt.g, tt.b, tt.a)
+		}
+		if got, want := tt.in.NRGBA(), (stdcolor.NRGBA{R: tt.r, G: tt.g, B: tt.b, A: tt.a}); got != want {
+			t.Errorf("%v: got %v, want %v", tt.in, got, want)
+		}
+	}
+}
+
+func TestStdlibRoundTrip(t *testing.T) {
+	for _, c := range []stdcolor.NRGBA{
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 12, G: 34, B: 56, A: 255},
+		{R: 200, G: 100, B: 50, A: 128},
+		{R: 0, G: 0, B: 0, A: 0},
+	} {
+		if got := FromNRGBA(c).NRGBA(); got != c {
+			t.Errorf("NRGBA %v: got %v after round trip", c, got)
+		}
+	}
+
+	for _, c := range []stdcolor.RGBA{
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 12, G: 34, B: 56, A: 255},
+		{R: 100, G: 50, B: 25, A: 128},
+		{R: 0, G: 0, B: 0, A: 0},
+	} {
+		if got := FromRGBA(c).ToRGBA(); got != c {
+			t.Errorf("RGBA %v: got %v after round trip", c, got)
+		}
+	}
+
+	// Both representations of the same semi-transparent color must agree.
+	nrgba := stdcolor.NRGBA{R: 200, G: 100, B: 50, A: 128}
+	rgba := stdcolor.RGBAModel.Convert(nrgba).(stdcolor.RGBA)
+	c1 := FromNRGBA(nrgba)
+	c2 := FromRGBA(rgba)
+	// Premultiplication loses precision, so allow an error of 1.
+	diff := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	if got := c2.NRGBA(); diff(got.R, nrgba.R) > 1 || diff(got.G, nrgba.G) > 1 || diff(got.B, nrgba.B) > 1 || got.A != nrgba.A {
+		t.Errorf("got %v, want %v", got, nrgba)
+	}
+	if c1.Alpha != c2.Alpha {
+		t.Errorf("got alpha %g, want %g", c2.Alpha, c1.Alpha)
+	}
+}
+
+func testImagePixels(n int) []stdcolor.RGBA {
+	pixels := make([]stdcolor.RGBA, n)
+	for i := range pixels {
+		// Premultiplied channels mustn't exceed alpha.
+		a := 255 - i%7*40
+		pixels[i] = stdcolor.RGBA{
+			R: uint8(i * 31 % (a + 1)),
+			G: uint8(i * 17 % (a + 1)),
+			B: uint8(i * 7 % (a + 1)),
+			A: uint8(a),
+		}
+	}
+	pixels = append(pixels, stdcolor.RGBA{})
+	return pixels
+}
+
+func TestConvertImageRGBA(t *testing.T) {
+	src := testImagePixels(1000)
+	for _, to := range []*Space{SRGB, DisplayP3, Oklch, Lab} {
+		dst := make([]Color, len(src))
+		ConvertImageRGBA(src, SRGB, to, dst)
+		for i, p := range src {
+			want := FromRGBA(p)
+			want = want.Convert(to)
+			if !approxValues(dst[i].Values, want.Values, 1e-9) || dst[i].Alpha != want.Alpha || dst[i].Space != to {
+				t.Errorf("%v -> %s: got %v, want %v", p, to.ID, dst[i], want)
+			}
+		}
+
+		back := make([]stdcolor.RGBA, len(dst))
+		ConvertColorsToRGBA(dst, SRGB, back)
+		for i := range dst {
+			if want := dst[i].ToRGBA(); back[i] != want {
+				t.Errorf("%v -> sRGB: got %v, want %v", dst[i], back[i], want)
+			}
+		}
+	}
+}
+
+func BenchmarkConvertImageRGBA(b *testing.B) {
+	src := testImagePixels(1920 * 1080)
+	dst := make([]Color, len(src))
+	b.Run("ConvertImageRGBA", func(b *testing.B) {
+		for range b.N {
+			ConvertImageRGBA(src, SRGB, Oklab, dst)
+		}
+	})
+	b.Run("FromRGBA", func(b *testing.B) {
+		for range b.N {
+			for i, p := range src {
+				c := FromRGBA(p)
+				dst[i] = c.Convert(Oklab)
+			}
+		}
+	})
+	b.Run("ConvertColorsToRGBA", func(b *testing.B) {
+		ConvertImageRGBA(src, SRGB, Oklab, dst)
+		out := make([]stdcolor.RGBA, len(dst))
+		b.ResetTimer()
+		for range b.N {
+			ConvertColorsToRGBA(dst, SRGB, out)
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package color
+
+import (
+	"math"
+	"slices"
+)
+
+// HarmonyScheme describes a color harmony, that is, a set of hues at fixed
+// angles to a base hue.
+type HarmonyScheme int
+
+const (
+	// Complementary is the hue opposite of the base hue.
+	Complementary HarmonyScheme = iota
+	// Triadic are the two hues that divide the hue circle into thirds.
+	Triadic
+	// Tetradic are the three hues that divide the hue circle into quarters.
+	Tetradic
+	// SplitComplementary are the two hues adjacent to the complementary hue,
+	// 150° and 210° from the base hue.
+	SplitComplementary
+	// Analogous are the two hues adjacent to the base hue, 30° to either side.
+	Analogous
+)
+
+var harmonyOffsets = [...][]float64{
+	Complementary:      {180},
+	Triadic:            {120, 240},
+	Tetradic:           {90, 180, 270},
+	SplitComplementary: {150, 210},
+	Analogous:          {-30, 30},
+}
+
+// Harmony returns the colors that form the color harmony described by scheme
+// together with base. The colors are computed by rotating the base color's hue
+// in [Oklch], preserving its lightness and chroma, and are returned in the
+// base color's color space. The base color itself isn't included in the
+// result.
+func Harmony(base *Color, scheme HarmonyScheme) []Color {
+	lch := base.Convert(Oklch)
+	offsets := harmonyOffsets[scheme]
+	out := make([]Color, len(offsets))
+	for i, off := range offsets {
+		c := lch
+		c.Values[2] = math.Mod(c.Values[2]+off+360, 360)
+		out[i] = c.Convert(base.Space)
+	}
+	return out
+}
+
+// DedupPalette reduces a palette by removing near-duplicate colors. Colors are
+// considered in order, and a color is kept only if its difference, as computed
+// by delta, to every color kept so far is at least threshold. That is, of a
+// group of near-duplicates, the first one is kept and the others are dropped,
+// rather than being averaged. Any of the color difference functions, such as
+// [DeltaEOK], can be used as delta.
+//
+// The returned slice is newly allocated and colors isn't modified.
+func DedupPalette(colors []Color, delta DeltaEFunc, threshold float64) []Color {
+	var out []Color
+outer:
+	for i := range colors {
+		for j := range out {
+			if delta(&colors[i], &out[j]) < threshold {
+				continue outer
+			}
+		}
+		out = append(out, colors[i])
+	}
+	return out
+}
+
+// NearestIn returns the index of the color in palette that is closest to c, as
+// measured by delta, as well as the difference between the two colors. Of
+// several equally close colors, the first one is returned. The search stops
+// early when it finds a color whose difference is zero. If palette is empty,
+// NearestIn returns -1 and +Inf.
+func NearestIn(c *Color, palette []Color, delta DeltaEFunc) (index int, d float64) {
+	index, d = -1, math.Inf(1)
+	for i := range palette {
+		if dd := delta(c, &palette[i]); dd < d {
+			index, d = i, dd
+			if d == 0 {
+				break
+			}
+		}
+	}
+	return index, d
+}
+
+// DistinctColors returns n colors that are easy to tell apart, such as for
+// categories in a chart. The colors are spread evenly around the hue circle of
+// [Oklch] at a moderate chroma, with alternating lightness so that colors with
+// adjacent hues differ in lightness, too. They are mapped to the sRGB gamut
+// using [GamutMapCSS] and returned in the in color space. The result is
+// deterministic.
+func DistinctColors(n int, in *Space) []Color {
+	const (
+		chroma     = 0.13
+		startHue   = 30
+		lightness1 = 0.75
+		lightness2 = 0.55
+	)
+	out := make([]Color, n)
+	for i := range out {
+		l := lightness1
+		switch {
+		case n%2 == 1 && i == n-1:
+			// The last color is adjacent to both the first and the second to
+			// last color, which, for odd n, have different lightnesses.
+			l = (lightness1 + lightness2) / 2
+		case i%2 == 1:
+			l = lightness2
+		}
+		h := math.Mod(startHue+float64(i)*360/float64(n), 360)
+		c := Make(Oklch, l, chroma, h, 1)
+		c = GamutMapCSS(&c, SRGB)
+		out[i] = c.Convert(in)
+	}
+	return out
+}
+
+// TonalPalette returns a tonal palette, as used by Material Design, derived
+// from base. Each color of the palette has the hue and chroma of base in
+// [HCT], but the tone (0 to 100) given by the corresponding entry of tones.
+// Where a tone can't be represented with base's chroma in sRGB, the chroma is
+// reduced as much as necessary, while preserving hue and tone. The colors are
+// returned in [SRGB].
+func TonalPalette(base *Color, tones []float64) []Color {
+	hct := base.Convert(HCT)
+	h, chroma := hct.Values[0], hct.Values[1]
+	out := make([]Color, len(tones))
+	for i, tone := range tones {
+		out[i] = hctToSRGB(h, chroma, tone, base.Alpha)
+	}
+	return out
+}
+
+// hctToSRGB returns the sRGB color with the given HCT hue and tone and the
+// highest chroma, up to chroma, that is in the sRGB gamut.
+func hctToSRGB(h, chroma, tone, alpha float64) Color {
+	at := func(chroma float64) Color {
+		c := Make(HCT, h, chroma, tone, alpha)
+		return c.Convert(SRGB)
+	}
+	if c := at(chroma); c.InGamut() {
+		return GamutClip(&c, SRGB)
+	}
+	// Binary search for the largest chroma that is in gamut. Chroma 0 is in
+	// gamut for all tones in [0, 100].
+	lo, hi := 0.0, chroma
+	for hi-lo > 0.01 {
+		mid := (lo + hi) / 2
+		if c := at(mid); c.InGamut() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	c := at(lo)
+	return GamutClip(&c, SRGB)
+}
+
+// SortByPerception returns a copy of colors, reordered so that adjacent colors
+// are similar, as measured by delta. This is useful for building smooth strips
+// of swatches or legends.
+//
+// Finding the order with the smallest total difference between adjacent colors
+// is an instance of the traveling salesman problem, so SortByPerception uses a
+// heuristic and doesn't guarantee an optimal order. It builds a path using the
+// nearest neighbor heuristic, starting at the first color, and improves it
+// with 2-opt moves. The total difference of the result is never larger than
+// that of the input order.
+func SortByPerception(colors []Color, delta DeltaEFunc) []Color {
+	n := len(colors)
+	if n < 3 {
+		return slices.Clone(colors)
+	}
+
+	dist := make([]float64, n*n)
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			d := delta(&colors[i], &colors[j])
+			dist[i*n+j] = d
+			dist[j*n+i] = d
+		}
+	}
+	d := func(i, j int) float64 { return dist[i*n+j] }
+	total := func(order []int) float64 {
+		var sum float64
+		for i := 1; i < len(order); i++ {
+			sum += d(order[i-1], order[i])
+		}
+		return sum
+	}
+
+	// Nearest neighbor.
+	order := make([]int, 0, n)
+	used := make([]bool, n)
+	cur := 0
+	used[0] = true
+	order = append(order, 0)
+	for len(order) < n {
+		best, bestD := -1, math.Inf(1)
+		for j := range n {
+			if !used[j] && d(cur, j) < bestD {
+				best, bestD = j, d(cur, j)
+			}
+		}
+		used[best] = true
+		order = append(order, best)
+		cur = best
+	}
+
+	// 2-opt: reverse order[i:j+1] if that shortens the path. Because the path
+	// is open, reversing a prefix or suffix only changes a single edge.
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				var before, after float64
+				if i > 0 {
+					before += d(order[i-1], order[i])
+					after += d(order[i-1], order[j])
+				}
+				if j < n-1 {
+					before += d(order[j], order[j+1])
+					after += d(order[i], order[j+1])
+				}
+				if after < before-1e-12 {
+					slices.Reverse(order[i : j+1])
+					improved = true
+				}
+			}
+		}
+	}
+
+	identity := make([]int, n)
+	for i := range identity {
+		identity[i] = i
+	}
+	if total(identity) <= total(order) {
+		order = identity
+	}
+	out := make([]Color, n)
+	for i, idx := range order {
+		out[i] = colors[idx]
+	}
+	return out
+}
+
+// Quantize reduces the colors of pixels to a palette of at most k colors,
+// using k-means clustering, and returns the palette as well as, for each
+// pixel, the index of its palette entry. Pixels are assigned to the closest
+// palette entry as measured by metric, and each entry is the [Average] in
+// [Oklab] of the pixels assigned to it. The palette is returned in Oklab.
+//
+// The initial palette is chosen by farthest-point traversal, starting with the
+// first pixel, which makes the result deterministic. The palette has fewer
+// than k colors if pixels has fewer than k distinct colors.
+//
+// Quantize panics if k is less than 1.
+func Quantize(pixels []Color, k int, metric DeltaEFunc) (palette []Color, indices []int) {
+	if k < 1 {
+		panic("k must be at least 1")
+	}
+	if len(pixels) == 0 {
+		return nil, nil
+	}
+
+	// Farthest-point initialization. minDist[i] is the distance of pixel i to
+	// the closest palette entry.
+	palette = append(palette, pixels[0].Convert(Oklab))
+	minDist := make([]float64, len(pixels))
+	for i := range pixels {
+		minDist[i] = metric(&pixels[i], &palette[0])
+	}
+	for len(palette) < k {
+		far := 0
+		for i, d := range minDist {
+			if d > minDist[far] {
+				far = i
+			}
+		}
+		if minDist[far] == 0 {
+			break
+		}
+		palette = append(palette, pixels[far].Convert(Oklab))
+		c := &palette[len(palette)-1]
+		for i := range pixels {
+			minDist[i] = min(minDist[i], metric(&pixels[i], c))
+		}
+	}
+
+	indices = make([]int, len(pixels))
+	kmeans(pixels, palette, indices, metric, 100)
+	return palette, indices
+}
+
+// kmeans refines palette using at most maxIterations iterations of Lloyd's
+// algorithm and stores the index of each pixel's palette entry in indices.
+// The indices always refer to the final palette, even if the algorithm
+// doesn't converge.
+func kmeans(pixels, palette []Color, indices []int, metric DeltaEFunc, maxIterations int) {
+	members := make([][]Color, len(palette))
+	for n := range maxIterations {
+		changed := n == 0
+		for i := range pixels {
+			if idx, _ := NearestIn(&pixels[i], palette, metric); idx != indices[i] {
+				indices[i] = idx
+				changed = true
+			}
+		}
+		if !changed || n == maxIterations-1 {
+			// Updating the palette now would invalidate the indices.
+			break
+		}
+		for j := range members {
+			members[j] = members[j][:0]
+		}
+		for i, idx := range indices {
+			members[idx] = append(members[idx], pixels[i])
+		}
+		for j, m := range members {
+			// Empty clusters keep their previous color.
+			if len(m) > 0 {
+				palette[j] = Average(m, Oklab)
+			}
+		}
+	}
+}
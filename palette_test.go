@@ -0,0 +1,311 @@
+package color
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// hueDistance returns the absolute angular distance between two hues.
+func hueDistance(h1, h2 float64) float64 {
+	d := math.Mod(math.Abs(h1-h2), 360)
+	return min(d, 360-d)
+}
+
+func TestHarmony(t *testing.T) {
+	base := Make(SRGB, 0.8, 0.4, 0.2, 1)
+	baseLCh := base.Convert(Oklch)
+
+	comp := Harmony(&base, Complementary)
+	if len(comp) != 1 {
+		t.Fatalf("got %d colors, want 1", len(comp))
+	}
+	if comp[0].Space != SRGB {
+		t.Errorf("got space %s, want %s", comp[0].Space.Name, SRGB.Name)
+	}
+	compLCh := comp[0].Convert(Oklch)
+	if d := hueDistance(compLCh.Values[2], baseLCh.Values[2]); math.Abs(d-180) > 1e-6 {
+		t.Errorf("got hue distance %g, want 180", d)
+	}
+	if math.Abs(compLCh.Values[0]-baseLCh.Values[0]) > 1e-9 || math.Abs(compLCh.Values[1]-baseLCh.Values[1]) > 1e-9 {
+		t.Errorf("got %v, want lightness and chroma of %v", compLCh, baseLCh)
+	}
+
+	tri := Harmony(&base, Triadic)
+	if len(tri) != 2 {
+		t.Fatalf("got %d colors, want 2", len(tri))
+	}
+	h1 := tri[0].Convert(Oklch).Values[2]
+	h2 := tri[1].Convert(Oklch).Values[2]
+	for _, d := range []float64{
+		hueDistance(h1, h2),
+		hueDistance(h1, baseLCh.Values[2]),
+		hueDistance(h2, baseLCh.Values[2]),
+	} {
+		if math.Abs(d-120) > 1e-6 {
+			t.Errorf("got hue distance %g, want 120", d)
+		}
+	}
+
+	for scheme, want := range map[HarmonyScheme]int{Tetradic: 3, SplitComplementary: 2, Analogous: 2} {
+		if got := len(Harmony(&base, scheme)); got != want {
+			t.Errorf("scheme %d: got %d colors, want %d", scheme, got, want)
+		}
+	}
+}
+
+func TestDedupPalette(t *testing.T) {
+	colors := []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0, 0, 1, 1),
+		Make(SRGB, 0.995, 0.005, 0, 1),
+		Make(SRGB, 0, 0.6, 0, 1),
+		Make(SRGB, 1, 0.01, 0.01, 1),
+		Make(SRGB, 1, 1, 1, 1),
+	}
+	got := DedupPalette(colors, DeltaEOK, 0.02)
+	want := []Color{colors[0], colors[1], colors[3], colors[5]}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// A threshold of zero keeps everything, even exact duplicates.
+	colors = append(colors, colors[0])
+	if got := DedupPalette(colors, DeltaEOK, 0); !slices.Equal(got, colors) {
+		t.Errorf("got %v, want %v", got, colors)
+	}
+
+	if got := DedupPalette(nil, DeltaEOK, 0.02); len(got) != 0 {
+		t.Errorf("got %v, want empty palette", got)
+	}
+}
+
+func TestNearestIn(t *testing.T) {
+	palette := []Color{
+		Make(SRGB, 0, 0, 0, 1),
+		Make(SRGB, 1, 1, 1, 1),
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0, 0, 1, 1),
+		Make(SRGB, 1, 0, 0, 1),
+	}
+	tests := []struct {
+		c     Color
+		index int
+	}{
+		{Make(SRGB, 0.1, 0.1, 0.1, 1), 0},
+		{Make(SRGB, 0.9, 0.95, 0.9, 1), 1},
+		{Make(SRGB, 0.8, 0.1, 0.2, 1), 2},
+		{Make(Oklch, 0.45, 0.3, 264, 1), 3},
+		// Exact matches are found, and of equal colors, the first one wins.
+		{Make(SRGB, 1, 0, 0, 1), 2},
+	}
+	for _, tt := range tests {
+		index, d := NearestIn(&tt.c, palette, DeltaEOK)
+		if index != tt.index {
+			t.Errorf("%v: got index %d, want %d", tt.c, index, tt.index)
+			continue
+		}
+		if want := DeltaEOK(&tt.c, &palette[tt.index]); d != want {
+			t.Errorf("%v: got difference %g, want %g", tt.c, d, want)
+		}
+	}
+
+	c := Make(SRGB, 0.5, 0.5, 0.5, 1)
+	if index, d := NearestIn(&c, nil, DeltaEOK); index != -1 || !math.IsInf(d, 1) {
+		t.Errorf("got (%d, %g), want (-1, +Inf)", index, d)
+	}
+}
+
+func TestDistinctColors(t *testing.T) {
+	for n := range 21 {
+		colors := DistinctColors(n, Oklch)
+		if len(colors) != n {
+			t.Errorf("n = %d: got %d colors", n, len(colors))
+			continue
+		}
+		floor := 0.1
+		if n > 12 {
+			floor = 0.06
+		}
+		for i := range colors {
+			if colors[i].Space != Oklch {
+				t.Errorf("n = %d: got space %s, want %s", n, colors[i].Space.Name, Oklch.Name)
+			}
+			if !colors[i].InGamutOf(SRGB) {
+				t.Errorf("n = %d: %v isn't in the sRGB gamut", n, colors[i])
+			}
+			for j := i + 1; j < len(colors); j++ {
+				if d := DeltaEOK(&colors[i], &colors[j]); d < floor {
+					t.Errorf("n = %d: colors %d and %d differ by %g, want at least %g", n, i, j, d, floor)
+				}
+			}
+		}
+	}
+
+	if a, b := DistinctColors(8, SRGB), DistinctColors(8, SRGB); !slices.Equal(a, b) {
+		t.Errorf("got different results for the same arguments: %v and %v", a, b)
+	}
+}
+
+func TestTonalPalette(t *testing.T) {
+	tones := []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99, 100}
+	for _, base := range []Color{
+		Make(SRGB, 0.4, 0.3, 0.8, 1),
+		Make(SRGB, 0.9, 0.2, 0.1, 1),
+		Make(DisplayP3, 0, 0.8, 0.4, 1),
+	} {
+		baseHCT := base.Convert(HCT)
+		palette := TonalPalette(&base, tones)
+		if len(palette) != len(tones) {
+			t.Fatalf("got %d colors, want %d", len(palette), len(tones))
+		}
+
+		black := Make(SRGB, 0, 0, 0, 1)
+		white := Make(SRGB, 1, 1, 1, 1)
+		if d := DeltaEOK(&palette[0], &black); d > 0.01 {
+			t.Errorf("%v: tone 0 is %v, want near black", base, palette[0])
+		}
+		if d := DeltaEOK(&palette[len(palette)-1], &white); d > 0.01 {
+			t.Errorf("%v: tone 100 is %v, want near white", base, palette[len(palette)-1])
+		}
+
+		for i, c := range palette {
+			if c.Space != SRGB || !c.InGamut() {
+				t.Errorf("%v: tone %g: got %v, want a color in the sRGB gamut", base, tones[i], c)
+			}
+			hct := c.Convert(HCT)
+			if math.Abs(hct.Values[2]-tones[i]) > 0.5 {
+				t.Errorf("%v: got tone %g, want %g", base, hct.Values[2], tones[i])
+			}
+			if hct.Values[1] > baseHCT.Values[1]+0.5 {
+				t.Errorf("%v: tone %g: got chroma %g, want at most %g", base, tones[i], hct.Values[1], baseHCT.Values[1])
+			}
+			// Hue is meaningless for colors that are close to achromatic.
+			if hct.Values[1] > 5 {
+				if d := hueDistance(hct.Values[0], baseHCT.Values[0]); d > 2 {
+					t.Errorf("%v: tone %g: got hue %g, want %g", base, tones[i], hct.Values[0], baseHCT.Values[0])
+				}
+			}
+		}
+	}
+}
+
+func TestSortByPerception(t *testing.T) {
+	red := Make(Oklch, 0.6, 0.2, 30, 1)
+	blue := Make(Oklch, 0.5, 0.2, 260, 1)
+	gradient := slices.Collect(Step(&red, &blue, Oklab, Oklab, 12))
+	shuffled := slices.Clone(gradient)
+	for i, j := range []int{7, 2, 10, 0, 5, 11, 3, 8, 1, 9, 4, 6} {
+		shuffled[i] = gradient[j]
+	}
+
+	total := func(colors []Color) float64 {
+		var sum float64
+		for i := 1; i < len(colors); i++ {
+			sum += DeltaEOK(&colors[i-1], &colors[i])
+		}
+		return sum
+	}
+
+	sorted := SortByPerception(shuffled, DeltaEOK)
+	if len(sorted) != len(shuffled) {
+		t.Fatalf("got %d colors, want %d", len(sorted), len(shuffled))
+	}
+	for _, c := range shuffled {
+		if !slices.Contains(sorted, c) {
+			t.Errorf("%v is missing from the result", c)
+		}
+	}
+	if got, in := total(sorted), total(shuffled); got > in {
+		t.Errorf("got total difference %g, want at most %g", got, in)
+	}
+	// The colors of a gradient can be put back into gradient order.
+	if got, want := total(sorted), total(gradient); math.Abs(got-want) > 1e-9 {
+		t.Errorf("got total difference %g, want %g", got, want)
+	}
+
+	// The input is already in the best possible order.
+	if got := SortByPerception(gradient, DeltaEOK); total(got) > total(gradient)+1e-12 {
+		t.Errorf("got total difference %g for sorted input, want %g", total(got), total(gradient))
+	}
+
+	if got := SortByPerception(nil, DeltaEOK); len(got) != 0 {
+		t.Errorf("got %v, want empty slice", got)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	centers := []Color{
+		Make(SRGB, 0.9, 0.1, 0.1, 1),
+		Make(SRGB, 0.1, 0.7, 0.2, 1),
+		Make(SRGB, 0.1, 0.2, 0.9, 1),
+		Make(SRGB, 0.95, 0.9, 0.3, 1),
+	}
+	// A synthetic image with four tight clusters of colors, interleaved.
+	var pixels []Color
+	var cluster []int
+	for i := range 200 {
+		j := i % len(centers)
+		c := centers[j]
+		off := float64(i%7-3) * 0.004
+		c.Values[0] += off
+		c.Values[2] -= off
+		pixels = append(pixels, c)
+		cluster = append(cluster, j)
+	}
+
+	palette, indices := Quantize(pixels, len(centers), DeltaEOK)
+	if len(palette) != len(centers) {
+		t.Fatalf("got %d palette entries, want %d", len(palette), len(centers))
+	}
+	if len(indices) != len(pixels) {
+		t.Fatalf("got %d indices, want %d", len(indices), len(pixels))
+	}
+
+	// Every cluster maps to its own palette entry, close to the cluster's
+	// center.
+	entryOf := map[int]int{}
+	for i, idx := range indices {
+		if prev, ok := entryOf[cluster[i]]; ok && prev != idx {
+			t.Fatalf("cluster %d is split between entries %d and %d", cluster[i], prev, idx)
+		}
+		entryOf[cluster[i]] = idx
+	}
+	seen := map[int]bool{}
+	for j, idx := range entryOf {
+		if seen[idx] {
+			t.Errorf("entry %d is shared by several clusters", idx)
+		}
+		seen[idx] = true
+		if d := DeltaEOK(&palette[idx], &centers[j]); d > 0.01 {
+			t.Errorf("cluster %d: got entry %v, want close to %v", j, palette[idx], centers[j])
+		}
+	}
+
+	// Asking for more colors than there are distinct pixels returns fewer.
+	palette, indices = Quantize(centers[:2], 5, DeltaEOK)
+	if len(palette) != 2 || !slices.Equal(indices, []int{0, 1}) {
+		t.Errorf("got palette %v and indices %v, want two entries", palette, indices)
+	}
+}
+
+func TestKmeansIterationLimit(t *testing.T) {
+	// A gray ramp with a poor initial palette needs several iterations to
+	// converge. Whenever kmeans stops early, the indices must still refer to
+	// the nearest entries of the returned palette.
+	var pixels []Color
+	for i := range 50 {
+		v := float64(i) / 49
+		pixels = append(pixels, Make(SRGB, v, v, v, 1))
+	}
+	for n := 1; n <= 5; n++ {
+		palette := []Color{pixels[0].Convert(Oklab), pixels[1].Convert(Oklab)}
+		indices := make([]int, len(pixels))
+		kmeans(pixels, palette, indices, DeltaEOK, n)
+		for i := range pixels {
+			if want, _ := NearestIn(&pixels[i], palette, DeltaEOK); indices[i] != want {
+				t.Errorf("%d iterations: pixel %d has index %d, want %d", n, i, indices[i], want)
+			}
+		}
+	}
+}
@@ -1,23 +1,106 @@
 package color
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
+// reNumber matches a number with an optional percent sign.
+const reNumber = `(?:[+-]?\d+|[+-]?\d*\.\d+(?:[eE][+-]?\d+)?)%?`
+
+// reValue matches a number, a number with an angle unit, or the 'none'
+// keyword, which denotes a missing component.
+const reValue = `(?:` + reNumber + `|` + reNumber + `(?:deg|rad|grad|turn)|none)`
+
 var reColor = regexp.MustCompile(`^color\(` +
 	`([a-zA-Z0-9-]+) ` +
-	`((?:[+-]?\d+|[+-]?\d*\.\d+(?:[eE][+-]?\d+)?)%?) ` +
-	`((?:[+-]?\d+|[+-]?\d*\.\d+(?:[eE][+-]?\d+)?)%?) ` +
-	`((?:[+-]?\d+|[+-]?\d*\.\d+(?:[eE][+-]?\d+)?)%?)` +
-	`(?: / ((?:[+-]?\d+|[+-]?\d*\.\d+(?:[eE][+-]?\d+)?)%?))?\);?$`)
+	`(` + reValue + `) ` +
+	`(` + reValue + `) ` +
+	`(` + reValue + `)` +
+	`(?: / (` + reValue + `))?\);?$`)
+
+// reLab matches the lab(), lch(), oklab(), and oklch() syntax.
+var reLab = regexp.MustCompile(`^(lab|lch|oklab|oklch)\(\s*` +
+	`(` + reValue + `)\s+` +
+	`(` + reValue + `)\s+` +
+	`(` + reValue + `)\s*` +
+	`(?:/\s*(` + reValue + `)\s*)?\);?$`)
+
+// reColorMix matches the color-mix() syntax. The two colors and their
+// percentages are matched as a whole and processed separately.
+var reColorMix = regexp.MustCompile(`^color-mix\(\s*in\s+([a-zA-Z0-9-]+)` +
+	`(?:\s+(shorter|longer|increasing|decreasing)\s+hue)?\s*,` +
+	`(.+)\);?$`)
+
+// reRGBLegacy matches the comma-separated rgb() and rgba() syntax.
+var reRGBLegacy = regexp.MustCompile(`^rgba?\(\s*` +
+	`(` + reNumber + `)\s*,\s*` +
+	`(` + reNumber + `)\s*,\s*` +
+	`(` + reNumber + `)\s*` +
+	`(?:,\s*(` + reNumber + `)\s*)?\);?$`)
+
+// reRGB matches the whitespace-separated rgb() and rgba() syntax. Unlike the
+// legacy syntax, it allows for missing components.
+var reRGB = regexp.MustCompile(`^rgba?\(\s*` +
+	`(` + reValue + `)\s+` +
+	`(` + reValue + `)\s+` +
+	`(` + reValue + `)\s*` +
+	`(?:/\s*(` + reValue + `)\s*)?\);?$`)
+
+// Errors returned by [ParseErr]. They are wrapped with additional context and
+// should be checked for with [errors.Is].
+var (
+	// ErrSyntax indicates that the input isn't in any of the supported
+	// formats.
+	ErrSyntax = errors.New("invalid color syntax")
+	// ErrUnknownSpace indicates that the input refers to a color space that
+	// hasn't been registered.
+	ErrUnknownSpace = errors.New("unknown color space")
+	// ErrOutOfRange indicates that a value is not finite or outside the range
+	// allowed by the syntax.
+	ErrOutOfRange = errors.New("value out of range")
+)
 
 // Parse parses colors in the CSS 'color()' format. The double dash for
-// non-standard color spaces is optional.
+// non-standard color spaces is optional. Strings starting with '#' are parsed
+// as hexadecimal colors by [ParseHex]. Additionally, the rgb() and rgba()
+// functions are supported, in both their legacy comma-separated and their
+// modern whitespace-separated forms, as are the lab(), lch(), oklab(), and
+// oklch() functions. The color-mix() function is parsed by [ParseColorMix].
+//
+// Hues may be specified with the deg, rad, grad, and turn units and are
+// converted to degrees. Except in the legacy rgb() syntax, any component,
+// including alpha, may be specified as 'none', which marks it as missing and is
+// represented as NaN.
+//
+// See [ParseErr] for a variant that reports why parsing failed.
 func Parse(s string) (Color, bool) {
+	c, err := ParseErr(s)
+	return c, err == nil
+}
+
+// ParseErr is like [Parse], but returns an error describing why parsing failed.
+// The error wraps one of [ErrSyntax], [ErrUnknownSpace], or [ErrOutOfRange].
+func ParseErr(s string) (Color, error) {
+	switch {
+	case strings.HasPrefix(s, "color-mix("):
+		return parseColorMix(s)
+	case strings.HasPrefix(s, "#"):
+		return parseHex(s)
+	case strings.HasPrefix(s, "rgb"):
+		return parseRGB(s)
+	case strings.HasPrefix(s, "lab"), strings.HasPrefix(s, "lch"),
+		strings.HasPrefix(s, "oklab"), strings.HasPrefix(s, "oklch"):
+		return parseLab(s)
+	}
+
 	m := reColor.FindStringSubmatch(s)
 	if m == nil {
-		return Color{}, false
+		return Color{}, syntaxError(s)
 	}
 
 	space := m[1]
@@ -26,65 +109,388 @@ func Parse(s string) (Color, bool) {
 	z := m[4]
 	a := m[5]
 
-	if space == "xyz" {
-		space = "xyz-d65"
-	}
 	cs, ok := LookupSpace(space)
 	if !ok {
-		return Make(SRGB, 0, 0, 0, 1), false
+		return Color{}, fmt.Errorf("%w %q", ErrUnknownSpace, space)
+	}
+
+	var values [3]float64
+	for i, v := range []string{x, y, z} {
+		f, err := parseCoord(cs, i, v)
+		if err != nil {
+			return Color{}, err
+		}
+		values[i] = f
+	}
+	alpha, err := parseAlpha(a)
+	if err != nil {
+		return Color{}, err
+	}
+
+	return Make(cs, values[0], values[1], values[2], alpha), nil
+}
+
+func syntaxError(s string) error {
+	return fmt.Errorf("%w: %q", ErrSyntax, s)
+}
+
+// ParseHex parses colors in the CSS hexadecimal notation, that is #rgb, #rgba,
+// #rrggbb, and #rrggbbaa. The returned color is in the [SRGB] color space.
+func ParseHex(s string) (Color, bool) {
+	c, err := parseHex(s)
+	return c, err == nil
+}
+
+func parseHex(in string) (Color, error) {
+	if len(in) == 0 || in[0] != '#' {
+		return Color{}, syntaxError(in)
+	}
+	s := in[1:]
+
+	var digits [8]byte
+	switch len(s) {
+	case 3, 4:
+		// Shorthand notation, each digit is duplicated.
+		for i := range len(s) {
+			digits[2*i] = s[i]
+			digits[2*i+1] = s[i]
+		}
+		if len(s) == 3 {
+			digits[6], digits[7] = 'f', 'f'
+		}
+	case 6, 8:
+		copy(digits[:], s)
+		if len(s) == 6 {
+			digits[6], digits[7] = 'f', 'f'
+		}
+	default:
+		return Color{}, syntaxError(in)
 	}
 
 	var values [4]float64
-	parseValue := func(idx int, s string) bool {
-		if idx == 3 && len(s) == 0 {
-			values[3] = 1
-			return true
-		}
-
-		if s[len(s)-1] == '%' {
-			f, err := strconv.ParseFloat(s[:len(s)-1], 64)
-			if err != nil {
-				// Even inputs that pass the regex can get here, e.g. because of
-				// absurdly large values.
-				return false
-			}
-			if f < 0 {
-				f = 0
-			}
-			if f > 100 {
-				f = 100
-			}
+	for i := range values {
+		v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return Color{}, syntaxError(in)
+		}
+		values[i] = float64(v) / 255
+	}
+	return Make(SRGB, values[0], values[1], values[2], values[3]), nil
+}
+
+// parseNumber parses a number that may be followed by a percent sign. It
+// reports whether the number was a percentage.
+func parseNumber(s string) (f float64, percent bool, err error) {
+	num := s
+	if len(num) > 0 && num[len(num)-1] == '%' {
+		num = num[:len(num)-1]
+		percent = true
+	}
+	f, err = strconv.ParseFloat(num, 64)
+	if err != nil {
+		// Even inputs that pass the regex can get here, e.g. because of
+		// absurdly large values.
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, false, fmt.Errorf("%w: %s", ErrOutOfRange, s)
+		}
+		return 0, false, syntaxError(s)
+	}
+	return f, percent, nil
+}
+
+// parseCoord parses the value of the idx'th coordinate of the color space cs.
+// Percentages are clamped to [0%, 100%] and mapped to the coordinate's
+// reference range. Angle coordinates may use the deg, rad, grad, and turn
+// units and are converted to degrees. The 'none' keyword is parsed as NaN.
+func parseCoord(cs *Space, idx int, s string) (float64, error) {
+	if s == "none" {
+		return math.NaN(), nil
+	}
+	num := s
+	scale := 0.0
+	for _, unit := range []struct {
+		name  string
+		scale float64
+	}{
+		// grad has to come before rad, as it shares the suffix.
+		{"deg", 1},
+		{"grad", 360.0 / 400.0},
+		{"rad", 180 / math.Pi},
+		{"turn", 360},
+	} {
+		if strings.HasSuffix(num, unit.name) {
+			num = num[:len(num)-len(unit.name)]
+			scale = unit.scale
+			break
+		}
+	}
+	f, percent, err := parseNumber(num)
+	if err != nil {
+		return 0, err
+	}
+	if scale != 0 {
+		if percent || !cs.Coords[idx].IsAngle {
+			return 0, syntaxError(s)
+		}
+		return f * scale, nil
+	}
+	if percent {
+		f = min(max(f, 0), 100) / 100
+		rng := cs.Coords[idx].RefRange
+		f = lerp(rng[0], rng[1], f)
+	}
+	return f, nil
+}
+
+// parseAlpha parses an alpha value, which may be a number or a percentage. An
+// empty string denotes full opacity, and the 'none' keyword is parsed as NaN.
+func parseAlpha(s string) (float64, error) {
+	if len(s) == 0 {
+		return 1, nil
+	}
+	if s == "none" {
+		return math.NaN(), nil
+	}
+	f, percent, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		f /= 100
+	}
+	return min(max(f, 0), 1), nil
+}
+
+// parseRGB parses colors in the CSS 'rgb()' and 'rgba()' formats. Channels may
+// be numbers in the range [0, 255] or percentages and are clamped to that
+// range. In the legacy comma-separated syntax, the channels must either all be
+// numbers or all be percentages.
+func parseRGB(s string) (Color, error) {
+	m := reRGBLegacy.FindStringSubmatch(s)
+	if m != nil {
+		percent := strings.HasSuffix(m[1], "%")
+		if strings.HasSuffix(m[2], "%") != percent || strings.HasSuffix(m[3], "%") != percent {
+			return Color{}, syntaxError(s)
+		}
+	} else {
+		m = reRGB.FindStringSubmatch(s)
+		if m == nil {
+			return Color{}, syntaxError(s)
+		}
+	}
+
+	var values [3]float64
+	for i := range values {
+		if m[i+1] == "none" {
+			values[i] = math.NaN()
+			continue
+		}
+		f, percent, err := parseNumber(m[i+1])
+		if err != nil {
+			return Color{}, err
+		}
+		if percent {
 			f /= 100
-			if idx == 3 {
-				values[3] = f
-			} else {
-				rng := cs.Coords[idx].RefRange
-				values[idx] = lerp(rng[0], rng[1], f)
-			}
 		} else {
-			f, err := strconv.ParseFloat(s, 64)
-			if err != nil {
-				// Even inputs that pass the regex can get here, e.g. because of
-				// absurdly large values.
-				return false
-			}
-			if idx == 3 {
-				if f < 0 {
-					f = 0
-				}
-				if f > 1 {
-					f = 1
-				}
-			}
-			values[idx] = f
+			f /= 255
+		}
+		values[i] = min(max(f, 0), 1)
+	}
+	alpha, err := parseAlpha(m[4])
+	if err != nil {
+		return Color{}, err
+	}
+	return Make(SRGB, values[0], values[1], values[2], alpha), nil
+}
+
+// parseLab parses colors in the CSS 'lab()', 'lch()', 'oklab()', and 'oklch()'
+// formats.
+func parseLab(s string) (Color, error) {
+	m := reLab.FindStringSubmatch(s)
+	if m == nil {
+		return Color{}, syntaxError(s)
+	}
+
+	var cs *Space
+	switch m[1] {
+	case "lab":
+		cs = Lab
+	case "lch":
+		cs = LCh
+	case "oklab":
+		cs = Oklab
+	case "oklch":
+		cs = Oklch
+	}
+
+	var values [3]float64
+	for i := range values {
+		f, err := parseLabCoord(cs, i, m[i+2])
+		if err != nil {
+			return Color{}, err
+		}
+		values[i] = f
+	}
+	alpha, err := parseAlpha(m[5])
+	if err != nil {
+		return Color{}, err
+	}
+	return Make(cs, values[0], values[1], values[2], alpha), nil
+}
+
+// parseLabCoord is like parseCoord, but follows the rules of the lab(), lch(),
+// oklab(), and oklch() functions for percentages: hues can't be percentages,
+// and the a and b coordinates, whose reference ranges are symmetric around
+// zero, map 0% to 0 and 100% to the upper end of the range. Negative
+// percentages aren't clamped for a and b.
+func parseLabCoord(cs *Space, idx int, s string) (float64, error) {
+	if !strings.HasSuffix(s, "%") {
+		return parseCoord(cs, idx, s)
+	}
+	coord := cs.Coords[idx]
+	if coord.IsAngle {
+		return 0, syntaxError(s)
+	}
+	if coord.RefRange[0] != -coord.RefRange[1] {
+		return parseCoord(cs, idx, s)
+	}
+	f, _, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	return f / 100 * coord.RefRange[1], nil
+}
+
+// ParseColorMix parses the CSS 'color-mix()' function, such as
+// 'color-mix(in oklch longer hue, #f00 30%, #00f)', and returns the mixed
+// color in the interpolation color space. The two colors may be in any format
+// supported by [Parse]. Hues in polar color spaces are interpolated using the
+// shorter path around the hue circle, unless specified otherwise.
+//
+// Percentages are normalized as per the CSS specification: a missing
+// percentage is 100% minus the other percentage, or 50% if both are missing.
+// If the percentages sum to less than 100%, the resulting color's alpha is
+// scaled by the sum. Like in CSS, colors are interpolated in premultiplied alpha
+// form.
+func ParseColorMix(s string) (Color, bool) {
+	c, err := parseColorMix(s)
+	return c, err == nil
+}
+
+func parseColorMix(s string) (Color, error) {
+	m := reColorMix.FindStringSubmatch(s)
+	if m == nil {
+		return Color{}, syntaxError(s)
+	}
+
+	space := m[1]
+	cs, ok := LookupSpace(space)
+	if !ok {
+		return Color{}, fmt.Errorf("%w %q", ErrUnknownSpace, space)
+	}
+
+	var hue HueInterpolation
+	switch m[2] {
+	case "", "shorter":
+		hue = ShorterHue
+	case "longer":
+		hue = LongerHue
+	case "increasing":
+		hue = IncreasingHue
+	case "decreasing":
+		hue = DecreasingHue
+	}
+
+	args := splitArgs(m[3])
+	if len(args) != 2 {
+		return Color{}, syntaxError(s)
+	}
+	var colors [2]Color
+	var percentages [2]float64
+	var hasPercentage [2]bool
+	for i, arg := range args {
+		c, p, hasP, err := parseMixArg(arg)
+		if err != nil {
+			return Color{}, err
 		}
-		return true
+		colors[i] = c
+		percentages[i] = p
+		hasPercentage[i] = hasP
 	}
 
-	parseValue(0, x)
-	parseValue(1, y)
-	parseValue(2, z)
-	parseValue(3, a)
+	p1, p2 := percentages[0], percentages[1]
+	switch {
+	case !hasPercentage[0] && !hasPercentage[1]:
+		p1, p2 = 50, 50
+	case !hasPercentage[0]:
+		p1 = 100 - p2
+	case !hasPercentage[1]:
+		p2 = 100 - p1
+	}
+	sum := p1 + p2
+	if sum == 0 {
+		return Color{}, fmt.Errorf("%w: percentages sum to zero", ErrOutOfRange)
+	}
+	alphaMult := 1.0
+	if sum < 100 {
+		alphaMult = sum / 100
+	}
+
+	out := mix(&colors[0], &colors[1], cs, p2/sum, interpolation{hue: hue, premultiplied: true})
+	out.Alpha *= alphaMult
+	return out, nil
+}
+
+// splitArgs splits s at commas that aren't nested inside parentheses.
+func splitArgs(s string) []string {
+	var out []string
+	depth := 0
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
 
-	return Make(cs, values[0], values[1], values[2], values[3]), true
+// parseMixArg parses a color with an optional leading or trailing percentage,
+// as used by color-mix().
+func parseMixArg(s string) (c Color, p float64, hasP bool, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Color{}, 0, false, syntaxError(s)
+	}
+	var pct string
+	if first := fields[0]; len(fields) > 1 && strings.HasSuffix(first, "%") {
+		pct = first
+		fields = fields[1:]
+	} else if last := fields[len(fields)-1]; len(fields) > 1 && strings.HasSuffix(last, "%") {
+		pct = last
+		fields = fields[:len(fields)-1]
+	}
+	if pct != "" {
+		f, percent, err := parseNumber(pct)
+		if err != nil {
+			return Color{}, 0, false, err
+		}
+		if !percent {
+			return Color{}, 0, false, syntaxError(s)
+		}
+		if f < 0 || f > 100 {
+			return Color{}, 0, false, fmt.Errorf("%w: %s", ErrOutOfRange, pct)
+		}
+		p = f
+		hasP = true
+	}
+	c, err = ParseErr(strings.Join(fields, " "))
+	return c, p, hasP, err
 }
@@ -1,7 +1,9 @@
 package color
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"testing"
 )
 
@@ -19,6 +21,11 @@ func FuzzParse(f *testing.F) {
 	f.Add(`color(oklab 0.1 0.2 0.3 / 40%)`)
 	f.Add(`color(oklab 0.1 0.2 0.3)`)
 	f.Add(`color(oklab 10% 0.2 0.3)`)
+	f.Add(`#ff000080`)
+	f.Add(`rgb(255 0 0 / 50%)`)
+	f.Add(`rgba(255, 0, 0, 0.5)`)
+	f.Add(`oklch(70% 0.15 120deg / 0.5)`)
+	f.Add(`color-mix(in oklch longer hue, #f00 30%, rgb(0, 0, 255))`)
 
 	f.Fuzz(func(t *testing.T, s string) {
 		Parse(s)
@@ -31,3 +38,377 @@ func ExampleParse() {
 	// Output:
 	// color(--lab 0.400000 -50.000000 0.200000) true
 }
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+	}{
+		{"#f00", Make(SRGB, 1, 0, 0, 1)},
+		{"#f008", Make(SRGB, 1, 0, 0, 0x88/255.0)},
+		{"#00ff00", Make(SRGB, 0, 1, 0, 1)},
+		{"#0000ff80", Make(SRGB, 0, 0, 1, 0x80/255.0)},
+		{"#FFFFFF", Make(SRGB, 1, 1, 1, 1)},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if !ok {
+			t.Errorf("%q: failed to parse", tt.in)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"#", "#ff", "#fffff", "#ggg", "#+ff", "f00"} {
+		if _, ok := ParseHex(in); ok {
+			t.Errorf("%q: unexpectedly parsed", in)
+		}
+	}
+}
+
+func TestParseRGB(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+	}{
+		{"rgb(255, 0, 0)", Make(SRGB, 1, 0, 0, 1)},
+		{"rgba(255,0,0,0.5)", Make(SRGB, 1, 0, 0, 0.5)},
+		{"rgba(0, 255, 0, 50%)", Make(SRGB, 0, 1, 0, 0.5)},
+		{"rgb(255 0 0)", Make(SRGB, 1, 0, 0, 1)},
+		{"rgb(0 0 255 / 0.25)", Make(SRGB, 0, 0, 1, 0.25)},
+		{"rgb(0 0 255/25%)", Make(SRGB, 0, 0, 1, 0.25)},
+		{"rgb(100% 50% 0%)", Make(SRGB, 1, 0.5, 0, 1)},
+		{"rgb(300 -10 0)", Make(SRGB, 1, 0, 0, 1)},
+		{"rgb(100%, 50%, 0%)", Make(SRGB, 1, 0.5, 0, 1)},
+		{"rgba(100%, 50%, 0%, 0.5)", Make(SRGB, 1, 0.5, 0, 0.5)},
+		// Only the legacy syntax forbids mixing numbers and percentages.
+		{"rgb(255 50% 0)", Make(SRGB, 1, 0.5, 0, 1)},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if !ok {
+			t.Errorf("%q: failed to parse", tt.in)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		"rgb(1, 2)",
+		"rgb(1 2 3, 4)",
+		"rgb(1, 2, 3 / 4)",
+		"rgb()",
+		"rgb(255, 50%, 0)",
+		"rgba(100%, 0, 0%, 0.5)",
+	} {
+		if _, ok := Parse(in); ok {
+			t.Errorf("%q: unexpectedly parsed", in)
+		}
+	}
+}
+
+func TestParseLab(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+	}{
+		{"lab(50 40 59)", Make(Lab, 50, 40, 59, 1)},
+		{"lab(50% 40 59)", Make(Lab, 50, 40, 59, 1)},
+		{"lch(52.2 72.2 50 / 0.5)", Make(LCh, 52.2, 72.2, 50, 0.5)},
+		{"lch(52.2 72.2 50deg)", Make(LCh, 52.2, 72.2, 50, 1)},
+		{"oklab(0.5 -0.1 0.1)", Make(Oklab, 0.5, -0.1, 0.1, 1)},
+		{"oklab(100% 0 0 / 25%)", Make(Oklab, 1, 0, 0, 0.25)},
+		{"oklch(0.7 0.15 120)", Make(Oklch, 0.7, 0.15, 120, 1)},
+		{"oklch(70% 0.15 120deg)", Make(Oklch, 0.7, 0.15, 120, 1)},
+		{"lab(50 0% 0%)", Make(Lab, 50, 0, 0, 1)},
+		{"lab(50 -50% 100%)", Make(Lab, 50, -62.5, 125, 1)},
+		{"oklab(0.5 100% -100%)", Make(Oklab, 0.5, 0.4, -0.4, 1)},
+		{"lch(50 50% 120)", Make(LCh, 50, 75, 120, 1)},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if !ok {
+			t.Errorf("%q: failed to parse", tt.in)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		"lab(50 40deg 59)",
+		"lab(50 40 59deg)",
+		"oklch(0.7, 0.15, 120)",
+		"lab(50 40)",
+		"oklch(0.7 0.15 50%)",
+	} {
+		if _, ok := Parse(in); ok {
+			t.Errorf("%q: unexpectedly parsed", in)
+		}
+	}
+}
+
+func TestParseColorMix(t *testing.T) {
+	approx := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	c, ok := Parse("color-mix(in srgb, #f00 30%, #00f)")
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	if want := Make(SRGB, 0.3, 0, 0.7, 1); !approx(c.Values[0], want.Values[0]) ||
+		c.Values[1] != want.Values[1] || !approx(c.Values[2], want.Values[2]) || c.Alpha != 1 {
+		t.Errorf("got %v, want %v", c, want)
+	}
+
+	// Percentages that don't sum to 100% are normalized, and the alpha is
+	// scaled if they sum to less than 100%.
+	c, ok = Parse("color-mix(in srgb, 20% #f00, #00f 20%)")
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	if want := Make(SRGB, 0.5, 0, 0.5, 0.4); c != want {
+		t.Errorf("got %v, want %v", c, want)
+	}
+
+	red := Make(SRGB, 1, 0, 0, 1)
+	blue := Make(SRGB, 0, 0, 1, 1)
+	h1 := red.Convert(Oklch).Values[2]
+	h2 := blue.Convert(Oklch).Values[2]
+	c, ok = Parse("color-mix(in oklch, rgb(255 0 0), color(srgb 0 0 1))")
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	if want := math.Mod((h1+360+h2)/2, 360); c.Space != Oklch || !approx(c.Values[2], want) {
+		t.Errorf("got %v, want hue %g", c, want)
+	}
+	c, ok = Parse("color-mix(in oklch longer hue, rgb(255 0 0), color(srgb 0 0 1))")
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	if want := (h1 + h2) / 2; !approx(c.Values[2], want) {
+		t.Errorf("got %v, want hue %g", c, want)
+	}
+
+	for _, in := range []string{
+		"color-mix(in srgb, #f00)",
+		"color-mix(in srgb, #f00, #00f, #0f0)",
+		"color-mix(in nope, #f00, #00f)",
+		"color-mix(in srgb, #f00 0%, #00f 0%)",
+		"color-mix(in srgb, #f00 120%, #00f)",
+		"color-mix(in srgb, #f00 10% 20%, #00f)",
+	} {
+		if _, ok := Parse(in); ok {
+			t.Errorf("%q: unexpectedly parsed", in)
+		}
+	}
+}
+
+func TestCSS(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want string
+	}{
+		{Make(SRGB, 1, 0.5, 0, 1), "rgb(255, 128, 0)"},
+		{Make(SRGB, 1.2, -0.1, 0.2, 0.5), "rgba(255, 0, 51, 0.5)"},
+		{Make(Oklch, 0.627955, 0.257683, 29.233885, 1), "oklch(0.627955 0.257683 29.233885)"},
+		{Make(Oklch, 0.5, 0.1, 120, 0.25), "oklch(0.5 0.1 120 / 0.25)"},
+		{Make(Lab, 50, -20.0000001, 30, 1), "lab(50 -20 30)"},
+		{Make(DisplayP3, 1, 0, 0, 1), "color(display-p3 1 0 0)"},
+		{Make(XYZ_D65, 0.5, 0.25, 0.125, 0.5), "color(xyz-d65 0.5 0.25 0.125 / 0.5)"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.CSS(); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.c, got, tt.want)
+		}
+	}
+
+	// Spaces unknown to CSS are converted to XYZ.
+	c := Make(XyY, 0.3127, 0.329, 0.5, 1)
+	parsed, ok := Parse(c.CSS())
+	if !ok {
+		t.Fatalf("couldn't parse %q", c.CSS())
+	}
+	if parsed.Space != XYZ_D65 || !parsed.ApproxEqual(&c, XyY, 1e-6) {
+		t.Errorf("got %v, want %v", parsed, c)
+	}
+
+	// Serializations round-trip through Parse.
+	for _, c := range []Color{
+		Make(SRGB, 0.2, 0.4, 0.6, 0.5),
+		Make(Oklab, 0.5, -0.1, 0.1, 1),
+		Make(LCh, 40, 60, 300, 0.8),
+		Make(Rec2020, 0.1, 0.2, 0.3, 1),
+	} {
+		s := c.CSS()
+		parsed, ok := Parse(s)
+		if !ok {
+			t.Errorf("couldn't parse %q", s)
+			continue
+		}
+		if !parsed.ApproxEqual(&c, c.Space, 1e-6) {
+			t.Errorf("%q: got %v, want %v", s, parsed, c)
+		}
+	}
+}
+
+func TestCSSMissing(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		c    Color
+		want string
+	}{
+		{Make(SRGB, 1, nan, 0, nan), "rgb(255 none 0 / none)"},
+		{Make(SRGB, nan, 0.5, 1.2, 0.5), "rgb(none 128 255 / 0.5)"},
+		{Make(SRGB, 1, 0, 0, nan), "rgb(255 0 0 / none)"},
+		{Make(Oklch, 0.5, 0.2, nan, 1), "oklch(0.5 0.2 none)"},
+		{Make(DisplayP3, nan, 0, 1, 1), "color(display-p3 none 0 1)"},
+	}
+	for _, tt := range tests {
+		s := tt.c.CSS()
+		if s != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.c, s, tt.want)
+		}
+		parsed, err := ParseErr(s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", s, err)
+			continue
+		}
+		if parsed.Space != tt.c.Space {
+			t.Errorf("%q: got space %s, want %s", s, parsed.Space.ID, tt.c.Space.ID)
+		}
+		// sRGB values are clipped and quantized to 8 bits.
+		got := append(parsed.Values[:], parsed.Alpha)
+		want := append(tt.c.Values[:], tt.c.Alpha)
+		for i := range got {
+			if math.IsNaN(got[i]) != math.IsNaN(want[i]) ||
+				math.Abs(got[i]-min(max(want[i], 0), 1)) > 1.0/255 {
+				t.Errorf("%q: got %v, want %v", s, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseErr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"color(srgb 1 0)", ErrSyntax},
+		{"not a color", ErrSyntax},
+		{"#ff00f", ErrSyntax},
+		{"#gg0000", ErrSyntax},
+		{"rgb(1, 2)", ErrSyntax},
+		{"oklch(0.5 0.1)", ErrSyntax},
+		{"color(nonexistent 1 0 0)", ErrUnknownSpace},
+		{"color-mix(in nonexistent, red, blue)", ErrUnknownSpace},
+		{"color-mix(in srgb, #f00, color(nonexistent 1 0 0))", ErrUnknownSpace},
+		{"color(srgb 1.0e999 0 0)", ErrOutOfRange},
+		{"rgb(0 0 0 / .5e999)", ErrOutOfRange},
+		{"color-mix(in srgb, #f00 150%, #00f)", ErrOutOfRange},
+		{"color-mix(in srgb, #f00 0%, #00f 0%)", ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		_, err := ParseErr(tt.in)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%q: got error %v, want %v", tt.in, err, tt.want)
+		}
+		if _, ok := Parse(tt.in); ok {
+			t.Errorf("%q: Parse succeeded", tt.in)
+		}
+	}
+
+	c, err := ParseErr("color(srgb 1 0 0)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := Make(SRGB, 1, 0, 0, 1); c != want {
+		t.Errorf("got %v, want %v", c, want)
+	}
+}
+
+func TestParseNone(t *testing.T) {
+	tests := []struct {
+		in      string
+		values  [3]float64
+		alpha   float64
+		missing [4]bool
+	}{
+		{"color(srgb none 0 0)", [3]float64{0, 0, 0}, 1, [4]bool{true, false, false, false}},
+		{"oklch(0.5 0.2 none)", [3]float64{0.5, 0.2, 0}, 1, [4]bool{false, false, true, false}},
+		{"lab(none none 20 / none)", [3]float64{0, 0, 20}, 0, [4]bool{true, true, false, true}},
+		{"rgb(255 none 0 / 0.5)", [3]float64{1, 0, 0}, 0.5, [4]bool{false, true, false, false}},
+	}
+	for _, tt := range tests {
+		c, err := ParseErr(tt.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.in, err)
+			continue
+		}
+		got := append(c.Values[:], c.Alpha)
+		want := append(tt.values[:], tt.alpha)
+		for i := range got {
+			if tt.missing[i] {
+				if !math.IsNaN(got[i]) {
+					t.Errorf("%q: component %d is %g, want NaN", tt.in, i, got[i])
+				}
+			} else if got[i] != want[i] {
+				t.Errorf("%q: component %d is %g, want %g", tt.in, i, got[i], want[i])
+			}
+		}
+	}
+
+	// Missing components aren't allowed in the legacy syntax.
+	if _, ok := Parse("rgb(255, none, 0)"); ok {
+		t.Errorf("parsed none in legacy rgb() syntax")
+	}
+
+	c, _ := Parse("oklch(0.5 0.2 none)")
+	if got, want := c.CSS(), "oklch(0.5 0.2 none)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := c.String(), "color(--oklch 0.500000 0.200000 none)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseHueUnits(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"oklch(0.5 0.1 0.5turn)", 180},
+		{"oklch(0.5 0.1 200grad)", 180},
+		{"oklch(0.5 0.1 3.14159rad)", 179.9998},
+		{"lch(50 30 90deg)", 90},
+		{"color(--oklch 0.5 0.1 0.25turn)", 90},
+		{"color(lch 50 30 -100grad)", -90},
+	}
+	for _, tt := range tests {
+		c, err := ParseErr(tt.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.in, err)
+			continue
+		}
+		if math.Abs(c.Values[2]-tt.want) > 1e-4 {
+			t.Errorf("%q: got hue %g, want %g", tt.in, c.Values[2], tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		"lab(50 30 90deg)",
+		"oklch(0.5turn 0.1 90)",
+		"color(srgb 1 0 0.5turn)",
+		"rgb(255 0 0 / 1turn)",
+		"oklch(0.5 0.1 50%deg)",
+	} {
+		if _, err := ParseErr(in); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: got error %v, want %v", in, err, ErrSyntax)
+		}
+	}
+}
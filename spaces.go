@@ -2,6 +2,7 @@ package color
 
 import (
 	"fmt"
+	"iter"
 	"math"
 	"slices"
 	"strings"
@@ -15,52 +16,137 @@ func init() {
 	RegisterSpace(DisplayP3)
 	RegisterSpace(LinearSRGB)
 	RegisterSpace(SRGB)
+	RegisterSpace(SRGBGamma22)
 	RegisterSpace(Oklab)
 	RegisterSpace(Oklch)
 	RegisterSpace(ProPhoto)
 	RegisterSpace(LinearProPhoto)
 	RegisterSpace(Lab)
 	RegisterSpace(LCh)
+	RegisterSpace(LinearRec2020)
+	RegisterSpace(Rec2020)
+	RegisterSpace(XyY)
 }
 
 var (
 	spacesMu sync.RWMutex
-	spaces   = map[string]*Space{}
+	// spaces and aliases are keyed by lowercase IDs.
+	spaces  = map[string]*Space{}
+	aliases = map[string]string{
+		"xyz": "xyz-d65",
+	}
 
 	infty = [2]float64{math.Inf(-1), math.Inf(1)}
 	norm  = [2]float64{0, 1}
 )
 
-// LookupSpace looks up a registered (see [RegisterSpace]) color space by ID.
+// LookupSpace looks up a registered (see [RegisterSpace]) color space by ID or
+// by alias (see [RegisterSpaceAlias]). Like identifiers in CSS, IDs are matched
+// case-insensitively. A leading double dash is ignored.
 func LookupSpace(id string) (*Space, bool) {
-	id = strings.TrimPrefix(id, "--")
+	id = strings.ToLower(strings.TrimPrefix(id, "--"))
 	spacesMu.RLock()
 	defer spacesMu.RUnlock()
-	cs, ok := spaces[id]
+	if cs, ok := spaces[id]; ok {
+		return cs, true
+	}
+	cs, ok := spaces[aliases[id]]
 	return cs, ok
 }
 
+// Spaces returns an iterator over all registered color spaces, sorted by ID.
+// The iterator operates on a snapshot taken when Spaces is called and isn't
+// affected by spaces registered afterwards.
+func Spaces() iter.Seq[*Space] {
+	spacesMu.RLock()
+	snapshot := make([]*Space, 0, len(spaces))
+	for _, cs := range spaces {
+		snapshot = append(snapshot, cs)
+	}
+	spacesMu.RUnlock()
+	slices.SortFunc(snapshot, func(a, b *Space) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return slices.Values(snapshot)
+}
+
+// RegisterSpaceAlias registers alias as an alternative ID for the color space
+// with the given ID, to be used by [LookupSpace] and [Parse]. The space doesn't
+// have to be registered yet. The alias "xyz" for "xyz-d65" is registered by
+// default.
+func RegisterSpaceAlias(alias, id string) {
+	spacesMu.Lock()
+	defer spacesMu.Unlock()
+	aliases[strings.ToLower(alias)] = strings.ToLower(id)
+}
+
 // RegisterSpace registers a color space. This allows it to be referenced
 // by ID in 'color()' expressions as parsed by [Parse] and looked up by
 // [LookupSpace].
 //
 // All color spaces provided by this package are automatically registered.
+// Attempts to register a space under an ID that is already in use are silently
+// ignored; see [RegisterSpaceErr] for a variant that reports them.
 func RegisterSpace(cs *Space) {
 	spacesMu.Lock()
 	defer spacesMu.Unlock()
 	registerSpace(cs)
 }
 
+// RegisterSpaceErr is like [RegisterSpace], but returns an error if a different
+// color space with the same ID (compared case-insensitively) has already been
+// registered, or if cs fails [Space.Validate]. Registering the same space more
+// than once isn't an error.
+func RegisterSpaceErr(cs *Space) error {
+	if err := cs.Validate(); err != nil {
+		return err
+	}
+	spacesMu.Lock()
+	defer spacesMu.Unlock()
+	if existing, ok := spaces[strings.ToLower(cs.ID)]; ok && existing != cs {
+		return fmt.Errorf("color space ID %q is already used by %s", cs.ID, existing.Name)
+	}
+	registerSpace(cs)
+	return nil
+}
+
+// OverrideSpace registers cs, replacing any color space previously registered
+// under the same ID. Base spaces are registered like by [RegisterSpace]. This
+// only affects lookups by ID, such as by [LookupSpace] and in 'color()'
+// expressions; it doesn't change the package's variables, such as [SRGB], nor
+// spaces that use the replaced space as their base.
+func OverrideSpace(cs *Space) {
+	spacesMu.Lock()
+	defer spacesMu.Unlock()
+	delete(spaces, strings.ToLower(cs.ID))
+	registerSpace(cs)
+}
+
+// UnregisterSpace removes the color space with the given ID from the registry
+// and reports whether it was registered. Like [LookupSpace], it matches IDs
+// case-insensitively. Spaces that use the removed space as their base remain
+// registered and functional; removing a base space that other registered
+// spaces refer to is the caller's responsibility.
+func UnregisterSpace(id string) bool {
+	id = strings.ToLower(strings.TrimPrefix(id, "--"))
+	spacesMu.Lock()
+	defer spacesMu.Unlock()
+	_, ok := spaces[id]
+	delete(spaces, id)
+	return ok
+}
+
 func registerSpace(cs *Space) {
-	if _, ok := spaces[cs.ID]; ok {
+	id := strings.ToLower(cs.ID)
+	if _, ok := spaces[id]; ok {
 		// Trying to register the same color space ID more than once might point
 		// to a mistake, but it might also be the result of us registering base
 		// spaces, so we can't panic here.
 		return
 	}
-	spaces[cs.ID] = cs
+	spaces[id] = cs
 	if cs.Base != nil {
-		if _, ok := spaces[cs.Base.ID]; !ok {
+		if _, ok := spaces[strings.ToLower(cs.Base.ID)]; !ok {
 			registerSpace(cs.Base)
 		}
 	}
@@ -83,7 +169,8 @@ func registerSpace(cs *Space) {
 // not have the intended effect.
 //
 // When creating new color spaces, you must call [Space.Init] once you're
-// done.
+// done. This includes spaces created by copying an existing space and
+// replacing its fields.
 type Space struct {
 	ID       string
 	Name     string
@@ -94,10 +181,27 @@ type Space struct {
 	ToBase   func(c *[3]float64) [3]float64
 
 	path []*Space
+	// toBaseMatrix and fromBaseMatrix are set if ToBase and FromBase are plain
+	// matrix multiplications. They allow Converter to collapse chains of
+	// matrices. Init clears them, because it can't know whether ToBase and
+	// FromBase still match them, and initMatrices sets them.
+	toBaseMatrix   *[3][3]float64
+	fromBaseMatrix *[3][3]float64
+}
+
+// initMatrices is like Init, but records that ToBase and FromBase are
+// multiplications with toBase and fromBase.
+func (cs *Space) initMatrices(toBase, fromBase *[3][3]float64) *Space {
+	cs.Init()
+	cs.toBaseMatrix = toBase
+	cs.fromBaseMatrix = fromBase
+	return cs
 }
 
 func (cs *Space) Init() *Space {
-	if cs.Coords == ([3]Coordinate{}) {
+	cs.toBaseMatrix = nil
+	cs.fromBaseMatrix = nil
+	if cs.Coords == ([3]Coordinate{}) && cs.Base != nil {
 		cs.Coords = cs.Base.Coords
 	}
 	if cs.White == nil && cs.Base != nil {
@@ -112,14 +216,7 @@ func (cs *Space) Init() *Space {
 	}
 
 	// if cs.GamutSpace == nil {
-	// 	var isPolar bool
-	// 	for _, coord := range cs.Coords {
-	// 		if coord.IsAngle {
-	// 			isPolar = true
-	// 			break
-	// 		}
-	// 	}
-	// 	if isPolar {
+	// 	if cs.IsPolar() {
 	// 		cs.GamutSpace = cs.Base
 	// 	} else {
 	// 		cs.GamutSpace = cs
@@ -137,8 +234,70 @@ func (cs *Space) Init() *Space {
 	return orig
 }
 
+// Validate checks that cs is well-formed: it must have been initialized with
+// [Space.Init], have coordinates, and its chain of base spaces must end at
+// [XYZ_D65], with every space along the way providing FromBase and ToBase.
+// Spaces that fail validation would otherwise cause panics during conversion.
+func (cs *Space) Validate() error {
+	if cs.path == nil {
+		return fmt.Errorf("color space %s hasn't been initialized", cs.Name)
+	}
+	if cs.Coords == ([3]Coordinate{}) {
+		return fmt.Errorf("color space %s has no coordinates", cs.Name)
+	}
+	seen := map[*Space]bool{}
+	for p := cs; p != XYZ_D65; p = p.Base {
+		if seen[p] {
+			return fmt.Errorf("color space %s has a cycle in its base spaces", cs.Name)
+		}
+		seen[p] = true
+		if p.Base == nil {
+			return fmt.Errorf("base spaces of color space %s end at %s instead of %s",
+				cs.Name, p.Name, XYZ_D65.Name)
+		}
+		if p.FromBase == nil || p.ToBase == nil {
+			return fmt.Errorf("color space %s lacks FromBase or ToBase", p.Name)
+		}
+	}
+	return nil
+}
+
+// IsPolar reports whether cs is a polar (cylindrical) color space, that is,
+// whether any of its coordinates is an angle, such as the hue in [Oklch] and
+// [LCh].
+func (cs *Space) IsPolar() bool {
+	for _, coord := range cs.Coords {
+		if coord.IsAngle {
+			return true
+		}
+	}
+	return false
+}
+
+// CoordIndex returns the index of the coordinate with the given name, such as
+// "Lightness" or "Hue". ok is false if the space has no such coordinate.
+func (cs *Space) CoordIndex(name string) (idx int, ok bool) {
+	for i, coord := range cs.Coords {
+		if coord.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// InGamut reports whether values are within the ranges of the space's
+// coordinates, allowing for a small amount of floating point error. Values that
+// are NaN or infinite are never in gamut, not even for angle coordinates.
 func (cs *Space) InGamut(values [3]float64) bool {
-	const ϵ = 0.000075
+	return cs.InGamutTol(values, 0.000075)
+}
+
+// InGamutTol is like [Space.InGamut], but allows values to exceed the ranges of
+// the space's coordinates by up to ϵ. An ϵ of zero performs a strict check.
+func (cs *Space) InGamutTol(values [3]float64, ϵ float64) bool {
+	if !isFinite(values) {
+		return false
+	}
 	// if cs.GamutSpace != cs {
 	// 	values = cs.Convert(cs.GamutSpace, values)
 	// 	return cs.GamutSpace.InGamut(values)
@@ -157,9 +316,76 @@ func (cs *Space) InGamut(values [3]float64) bool {
 	return true
 }
 
+// Convert converts coordinates from cs to the space to.
+//
+// Non-finite values don't cause Convert to panic. A NaN or infinite
+// coordinate results in NaN or infinite values in every output coordinate
+// that depends on it. For example, converting sRGB with a NaN red coordinate
+// to linear sRGB only affects the red coordinate, but converting it to Oklab
+// affects all three.
 func (cs *Space) Convert(to *Space, coords [3]float64) [3]float64 {
 	ourPath := cs.path
 	theirPath := to.path
+	connIdx := cs.connection(to)
+
+	// Convert from our space to the connection space
+	for i := len(ourPath) - 1; i > connIdx; i-- {
+		coords = ourPath[i].ToBase(&coords)
+	}
+	// Convert from connection space to destination space
+	for i := connIdx + 1; i < len(theirPath); i++ {
+		coords = theirPath[i].FromBase(&coords)
+	}
+
+	return coords
+}
+
+// convertInPlace is like Convert, but uses coords for intermediate results.
+func (cs *Space) convertInPlace(to *Space, coords *[3]float64) {
+	ourPath := cs.path
+	theirPath := to.path
+	connIdx := cs.connection(to)
+
+	for i := len(ourPath) - 1; i > connIdx; i-- {
+		*coords = ourPath[i].ToBase(coords)
+	}
+	for i := connIdx + 1; i < len(theirPath); i++ {
+		*coords = theirPath[i].FromBase(coords)
+	}
+}
+
+// ConvertSlice converts each triple in coords from cs to the space to, storing
+// the results in dst, which must be at least as long as coords. dst and coords
+// may be the same slice, in which case coordinates are converted in place. The
+// results are identical to those of calling [Space.Convert] on each element.
+//
+// ConvertSlice doesn't modify any shared state and may be called concurrently
+// on disjoint slices.
+func (cs *Space) ConvertSlice(to *Space, coords [][3]float64, dst [][3]float64) {
+	if len(dst) < len(coords) {
+		panic("destination is too small")
+	}
+	ourPath := cs.path
+	theirPath := to.path
+	connIdx := cs.connection(to)
+
+	for j := range coords {
+		c := coords[j]
+		for i := len(ourPath) - 1; i > connIdx; i-- {
+			c = ourPath[i].ToBase(&c)
+		}
+		for i := connIdx + 1; i < len(theirPath); i++ {
+			c = theirPath[i].FromBase(&c)
+		}
+		dst[j] = c
+	}
+}
+
+// connection returns the index of the connection space of cs and to in their
+// paths.
+func (cs *Space) connection(to *Space) int {
+	ourPath := cs.path
+	theirPath := to.path
 
 	// Determine the connection space by finding the lowest common ancestor of
 	// the source and destination spaces in the color space tree.
@@ -176,17 +402,7 @@ func (cs *Space) Convert(to *Space, coords [3]float64) [3]float64 {
 		panic(fmt.Sprintf("internal error: couldn't find connection space for %s and %s",
 			cs.Name, to.Name))
 	}
-
-	// Convert from our space to the connection space
-	for i := len(ourPath) - 1; i > connIdx; i-- {
-		coords = ourPath[i].ToBase(&coords)
-	}
-	// Convert from connection space to destination space
-	for i := connIdx + 1; i < len(theirPath); i++ {
-		coords = theirPath[i].FromBase(&coords)
-	}
-
-	return coords
+	return connIdx
 }
 
 // NewXYZSpace returns a new CIE XYZ color space with the specified name, ID, and
@@ -211,7 +427,7 @@ func NewXYZSpace(name, id string, white *Chromaticity) *Space {
 		ToBase: func(c *[3]float64) [3]float64 {
 			return Adapt(c, &toD65)
 		},
-	}).Init()
+	}).initMatrices(&toD65, &fromD65)
 }
 
 var XYZ_D50 = NewXYZSpace("XYZ D50", "xyz-d50", WhitesCSSD50)
@@ -227,6 +443,39 @@ var XYZ_D65 = (&Space{
 	White: WhitesSRGBD65,
 }).Init()
 
+// XyY is the CIE xyY color space, which describes colors by their xy
+// chromaticity and their luminance Y. The chromaticity of black is undefined;
+// it is mapped to the chromaticity of the D65 white point.
+var XyY = (&Space{
+	ID:   "xyy",
+	Name: "xyY",
+	Coords: [3]Coordinate{
+		{Name: "x", Range: infty, RefRange: norm},
+		{Name: "y", Range: infty, RefRange: norm},
+		{Name: "Y", Range: infty, RefRange: norm},
+	},
+	Base: XYZ_D65,
+	FromBase: func(c *[3]float64) [3]float64 {
+		sum := c[0] + c[1] + c[2]
+		if sum == 0 {
+			white := XYZ_D65.White
+			return [3]float64{white.X, white.Y, c[1]}
+		}
+		return [3]float64{c[0] / sum, c[1] / sum, c[1]}
+	},
+	ToBase: func(c *[3]float64) [3]float64 {
+		x, y, Y := c[0], c[1], c[2]
+		if y == 0 {
+			return [3]float64{0, 0, 0}
+		}
+		return [3]float64{
+			x * Y / y,
+			Y,
+			(1 - x - y) * Y / y,
+		}
+	},
+}).Init()
+
 var LinearDisplayP3 = newRGBSpace(
 	&rgbSpace{
 		ID:   "display-p3-linear",
@@ -289,12 +538,12 @@ func newRGBSpace(space *rgbSpace) *Space {
 		Coords: RGBCoordinates,
 		Base:   space.Base,
 		ToBase: func(c *[3]float64) [3]float64 {
-			return mulVecMat(c, &space.ToBase)
+			return MulVecMat(c, &space.ToBase)
 		},
 		FromBase: func(c *[3]float64) [3]float64 {
-			return mulVecMat(c, &space.FromBase)
+			return MulVecMat(c, &space.FromBase)
 		},
-	}).Init()
+	}).initMatrices(&space.ToBase, &space.FromBase)
 }
 
 var SRGB = (&Space{
@@ -305,44 +554,73 @@ var SRGB = (&Space{
 		// TODO(dh): should this use the piecewise function, or a flat 2.2
 		// gamma? See discussion in
 		// https://gitlab.freedesktop.org/pq/color-and-hdr/-/issues/12
+		return [3]float64{SRGBEncode(c[0]), SRGBEncode(c[1]), SRGBEncode(c[2])}
+	},
+	ToBase: func(c *[3]float64) [3]float64 {
+		// TODO(dh): same concern as FromBase
+		return [3]float64{SRGBDecode(c[0]), SRGBDecode(c[1]), SRGBDecode(c[2])}
+	},
+}).Init()
 
+// SRGBGamma22 is like [SRGB], but uses a pure power curve with a gamma of 2.2
+// instead of sRGB's piecewise transfer function. Many displays are calibrated
+// to it, and some legacy pipelines use it in place of the sRGB curve. The two
+// differ the most for dark colors. Negative values are encoded symmetrically,
+// preserving their sign.
+var SRGBGamma22 = (&Space{
+	ID:   "srgb-gamma22",
+	Name: "sRGB (gamma 2.2)",
+	Base: LinearSRGB,
+	FromBase: func(c *[3]float64) [3]float64 {
 		f := func(ch float64) float64 {
-			var sign float64
-			if ch < 0 {
-				sign = -1.0
-			} else {
-				sign = 1.0
-			}
-			abs := ch * sign
-
-			if abs > 0.0031308 {
-				return sign * (1.055*(math.Pow(abs, 1.0/2.4)) - 0.055)
-			} else {
-				return 12.92 * ch
-			}
+			return math.Copysign(math.Pow(math.Abs(ch), 1/2.2), ch)
 		}
 		return [3]float64{f(c[0]), f(c[1]), f(c[2])}
 	},
 	ToBase: func(c *[3]float64) [3]float64 {
-		// TODO(dh): same concern as FromBase
 		f := func(ch float64) float64 {
-			var sign float64
-			if ch < 0 {
-				sign = -1
-			} else {
-				sign = 1
-			}
-			abs := ch * sign
-			if abs <= 0.04045 {
-				return ch / 12.92
-			} else {
-				return sign * math.Pow((abs+0.055)/1.055, 2.4)
-			}
+			return math.Copysign(math.Pow(math.Abs(ch), 2.2), ch)
 		}
 		return [3]float64{f(c[0]), f(c[1]), f(c[2])}
 	},
 }).Init()
 
+// SRGBEncode applies the piecewise sRGB transfer function to a linear-light
+// value, as used when converting from [LinearSRGB] to [SRGB]. Negative values
+// are encoded symmetrically, preserving their sign.
+func SRGBEncode(linear float64) float64 {
+	var sign float64
+	if linear < 0 {
+		sign = -1.0
+	} else {
+		sign = 1.0
+	}
+	abs := linear * sign
+
+	if abs > 0.0031308 {
+		return sign * (1.055*(math.Pow(abs, 1.0/2.4)) - 0.055)
+	} else {
+		return 12.92 * linear
+	}
+}
+
+// SRGBDecode is the inverse of [SRGBEncode], converting a gamma-encoded sRGB
+// value to linear light.
+func SRGBDecode(encoded float64) float64 {
+	var sign float64
+	if encoded < 0 {
+		sign = -1
+	} else {
+		sign = 1
+	}
+	abs := encoded * sign
+	if abs <= 0.04045 {
+		return encoded / 12.92
+	} else {
+		return sign * math.Pow((abs+0.055)/1.055, 2.4)
+	}
+}
+
 // Matrices have been recalculated for consistent reference white;
 // see https://github.com/w3c/csswg-drafts/issues/6642#issuecomment-943521484
 var (
@@ -381,25 +659,25 @@ var Oklab = (&Space{
 	},
 	Base: XYZ_D65,
 	FromBase: func(c *[3]float64) [3]float64 {
-		lms := mulVecMat(c, &oklabXyzToLms)
+		lms := MulVecMat(c, &oklabXyzToLms)
 
 		lms_ := [3]float64{
 			math.Cbrt(lms[0]),
 			math.Cbrt(lms[1]),
 			math.Cbrt(lms[2]),
 		}
-		lab := mulVecMat(&lms_, &oklabLmsToLab)
+		lab := MulVecMat(&lms_, &oklabLmsToLab)
 		return lab
 	},
 	ToBase: func(c *[3]float64) [3]float64 {
-		lms := mulVecMat(c, &oklabLabToLms)
+		lms := MulVecMat(c, &oklabLabToLms)
 		lms_ := [3]float64{
 			lms[0] * lms[0] * lms[0],
 			lms[1] * lms[1] * lms[1],
 			lms[2] * lms[2] * lms[2],
 		}
 
-		xyz := mulVecMat(&lms_, &oklabLmsToXyz)
+		xyz := MulVecMat(&lms_, &oklabLmsToXyz)
 		return xyz
 	},
 }).Init()
@@ -414,7 +692,7 @@ var Oklch = (&Space{
 	},
 	Base: Oklab,
 	FromBase: func(c *[3]float64) [3]float64 {
-		return labToLCH(c, 0.8/1e5)
+		return labToLCH(c, oklabAchromaticϵ)
 	},
 	ToBase: LCh.ToBase,
 }).Init()
@@ -489,9 +767,9 @@ var Lab = (&Space{
 		}
 
 		white := WhitesCSSD50.XYZ()
-		x /= white[0]
-		y /= white[1]
-		z /= white[2]
+		x *= white[0]
+		y *= white[1]
+		z *= white[2]
 
 		return [3]float64{x, y, z}
 	},
@@ -507,7 +785,7 @@ var LCh = (&Space{
 	},
 	Base: Lab,
 	FromBase: func(c *[3]float64) [3]float64 {
-		return labToLCH(c, 250.0/1e5)
+		return labToLCH(c, labAchromaticϵ)
 	},
 	ToBase: func(cl *[3]float64) [3]float64 {
 		// XXX handle achromatic h
@@ -521,11 +799,43 @@ var LCh = (&Space{
 	},
 }).Init()
 
+// Constants of Ottosson's toe function.
+const (
+	oklabToeK1 = 0.206
+	oklabToeK2 = 0.03
+	oklabToeK3 = (1 + oklabToeK1) / (1 + oklabToeK2)
+)
+
+// OklabToe maps the lightness L of [Oklab] to the reference lightness Lr, using
+// the toe function Björn Ottosson introduced for Okhsl and Okhsv. Lr matches
+// the lightness L* of CIELAB more closely than L does, particularly for dark
+// colors. It maps 0 to 0 and 1 to 1.
+func OklabToe(l float64) float64 {
+	x := oklabToeK3*l - oklabToeK1
+	return 0.5 * (x + math.Sqrt(x*x+4*oklabToeK2*oklabToeK3*l))
+}
+
+// OklabToeInv is the inverse of [OklabToe], mapping the reference lightness Lr
+// to the lightness L of [Oklab].
+func OklabToeInv(lr float64) float64 {
+	return (lr*lr + oklabToeK1*lr) / (oklabToeK3 * (lr + oklabToeK2))
+}
+
+// Thresholds for the a and b coordinates below which colors are considered
+// achromatic.
+const (
+	oklabAchromaticϵ = 0.8 / 1e5
+	labAchromaticϵ   = 250.0 / 1e5
+)
+
+func isAchromatic(a, b, ϵ float64) bool {
+	return math.Abs(a) < ϵ && math.Abs(b) < ϵ
+}
+
 func labToLCH(lab *[3]float64, ϵ float64) [3]float64 {
 	l, a, b := lab[0], lab[1], lab[2]
-	achromatic := math.Abs(a) < ϵ && math.Abs(b) < ϵ
 	var c, h float64
-	if achromatic {
+	if isAchromatic(a, b, ϵ) {
 		c = 0
 		// XXX color.js uses null for achromatic
 		h = 0
@@ -555,6 +865,11 @@ var LinearProPhoto = newRGBSpace(
 	},
 )
 
+// proPhotoEt is the breakpoint of the ProPhoto transfer function between the
+// linear and the power segments, in linear light. Encoded, the breakpoint is
+// at 16·Et.
+const proPhotoEt = 1.0 / 512.0
+
 var ProPhoto = (&Space{
 	ID:     "prophoto-rgb",
 	Name:   "ProPhoto",
@@ -562,7 +877,7 @@ var ProPhoto = (&Space{
 	Coords: RGBCoordinates,
 	ToBase: func(c *[3]float64) [3]float64 {
 		f := func(v float64) float64 {
-			if v < 16.0/512.0 {
+			if v < 16*proPhotoEt {
 				return v / 16.0
 			} else {
 				return math.Pow(v, 1.8)
@@ -576,7 +891,7 @@ var ProPhoto = (&Space{
 	},
 	FromBase: func(c *[3]float64) [3]float64 {
 		f := func(v float64) float64 {
-			if v >= 1.0/512.0 {
+			if v >= proPhotoEt {
 				return math.Pow(v, (1.0 / 1.8))
 			} else {
 				return 16 * v
@@ -590,7 +905,99 @@ var ProPhoto = (&Space{
 	},
 }).Init()
 
-func mulVecMat(vec *[3]float64, m *[3][3]float64) [3]float64 {
+var LinearRec2020 = newRGBSpace(
+	&rgbSpace{
+		ID:   "rec2020-linear",
+		Name: "Linear Rec. 2020",
+		Base: XYZ_D65,
+		ToBase: [3][3]float64{
+			{63426534.0 / 99577255.0, 20160776.0 / 139408157.0, 47086771.0 / 278816314.0},
+			{26158966.0 / 99577255.0, 472592308.0 / 697040785.0, 8267143.0 / 139408157.0},
+			{0, 19567812.0 / 697040785.0, 295819943.0 / 278816314.0},
+		},
+		FromBase: [3][3]float64{
+			{30757411.0 / 17917100.0, -6372589.0 / 17917100.0, -4539589.0 / 17917100.0},
+			{-19765991.0 / 29648200.0, 47925759.0 / 29648200.0, 467509.0 / 29648200.0},
+			{792561.0 / 44930125.0, -1921689.0 / 44930125.0, 42328811.0 / 44930125.0},
+		},
+	},
+)
+
+var Rec2020 = (&Space{
+	ID:     "rec2020",
+	Name:   "Rec. 2020",
+	Base:   LinearRec2020,
+	Coords: RGBCoordinates,
+	ToBase: func(c *[3]float64) [3]float64 {
+		const (
+			α = 1.09929682680944
+			β = 0.018053968510807
+		)
+		f := func(v float64) float64 {
+			if v < β*4.5 {
+				return v / 4.5
+			} else {
+				return math.Pow((v+α-1)/α, 1/0.45)
+			}
+		}
+		return [3]float64{
+			f(c[0]),
+			f(c[1]),
+			f(c[2]),
+		}
+	},
+	FromBase: func(c *[3]float64) [3]float64 {
+		const (
+			α = 1.09929682680944
+			β = 0.018053968510807
+		)
+		f := func(v float64) float64 {
+			if v >= β {
+				return α*math.Pow(v, 0.45) - (α - 1)
+			} else {
+				return 4.5 * v
+			}
+		}
+		return [3]float64{
+			f(c[0]),
+			f(c[1]),
+			f(c[2]),
+		}
+	},
+}).Init()
+
+func isFinite(values [3]float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// InvertMat3 returns the inverse of m. ok is false if m is singular.
+func InvertMat3(m *[3][3]float64) (inv [3][3]float64, ok bool) {
+	a, b, c := m[0][0], m[0][1], m[0][2]
+	d, e, f := m[1][0], m[1][1], m[1][2]
+	g, h, i := m[2][0], m[2][1], m[2][2]
+
+	// Cofactors of the first row
+	A := e*i - f*h
+	B := f*g - d*i
+	C := d*h - e*g
+	det := a*A + b*B + c*C
+	if det == 0 {
+		return inv, false
+	}
+	return [3][3]float64{
+		{A / det, (c*h - b*i) / det, (b*f - c*e) / det},
+		{B / det, (a*i - c*g) / det, (c*d - a*f) / det},
+		{C / det, (b*g - a*h) / det, (a*e - b*d) / det},
+	}, true
+}
+
+// MulVecMat returns the product of the matrix m and the column vector vec.
+func MulVecMat(vec *[3]float64, m *[3][3]float64) [3]float64 {
 	return [3]float64{
 		m[0][0]*vec[0] + m[0][1]*vec[1] + m[0][2]*vec[2],
 		m[1][0]*vec[0] + m[1][1]*vec[1] + m[1][2]*vec[2],
@@ -598,7 +1005,9 @@ func mulVecMat(vec *[3]float64, m *[3][3]float64) [3]float64 {
 	}
 }
 
-func mulMatMat(m1, m2 *[3][3]float64) [3][3]float64 {
+// MulMatMat returns the matrix product m1·m2. Multiplying a vector with the
+// result is the same as multiplying it with m2 and then with m1.
+func MulMatMat(m1, m2 *[3][3]float64) [3][3]float64 {
 	return [3][3]float64{
 		{
 			m1[0][0]*m2[0][0] + m1[0][1]*m2[1][0] + m1[0][2]*m2[2][0],
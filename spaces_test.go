@@ -0,0 +1,477 @@
+package color
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestXyY(t *testing.T) {
+	for _, c := range []Color{
+		Make(SRGB, 1, 0, 0, 1),
+		Make(SRGB, 0.2, 0.4, 0.6, 1),
+		Make(DisplayP3, 0, 1, 0, 1),
+		Make(XYZ_D65, 0.3, 0.2, 0.9, 1),
+	} {
+		xyY := c.Convert(XyY)
+		back := xyY.Convert(c.Space)
+		for i := range back.Values {
+			if math.Abs(back.Values[i]-c.Values[i]) > 1e-12 {
+				t.Errorf("%v didn't round-trip, got %v", c, back)
+				break
+			}
+		}
+	}
+
+	white := Make(SRGB, 1, 1, 1, 1)
+	got := white.Convert(XyY)
+	if math.Abs(got.Values[0]-0.3127) > 1e-4 || math.Abs(got.Values[1]-0.3290) > 1e-4 || math.Abs(got.Values[2]-1) > 1e-12 {
+		t.Errorf("got %v, want (0.3127, 0.3290, 1)", got)
+	}
+
+	// Black has no chromaticity and falls back to the white point.
+	black := Make(XYZ_D65, 0, 0, 0, 1)
+	if got, want := black.Convert(XyY), Make(XyY, 0.3127, 0.3290, 0, 1); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	blackXyY := Make(XyY, 0.3127, 0.3290, 0, 1)
+	if got := blackXyY.Convert(XYZ_D65); got != black {
+		t.Errorf("got %v, want %v", got, black)
+	}
+	noChroma := Make(XyY, 0, 0, 0, 1)
+	if got := noChroma.Convert(XYZ_D65); got != black {
+		t.Errorf("got %v, want %v", got, black)
+	}
+}
+
+func TestProPhotoRoundTrip(t *testing.T) {
+	// Sweep across the breakpoint of the transfer function, both in linear
+	// and in encoded values.
+	for _, center := range []float64{1.0 / 512, 16.0 / 512} {
+		for i := -100; i <= 100; i++ {
+			v := center + float64(i)*1e-5
+			lin := Make(LinearProPhoto, v, v, v, 1)
+			enc := lin.Convert(ProPhoto)
+			back := enc.Convert(LinearProPhoto)
+			if math.Abs(back.Values[0]-v) > 1e-15 {
+				t.Errorf("linear %g: got %g after round trip", v, back.Values[0])
+			}
+
+			enc = Make(ProPhoto, v, v, v, 1)
+			lin = enc.Convert(LinearProPhoto)
+			back = lin.Convert(ProPhoto)
+			if math.Abs(back.Values[0]-v) > 1e-15 {
+				t.Errorf("encoded %g: got %g after round trip", v, back.Values[0])
+			}
+		}
+	}
+}
+
+func convertSliceTestCoords() [][3]float64 {
+	var coords [][3]float64
+	for r := 0.0; r <= 1; r += 0.125 {
+		for g := 0.0; g <= 1; g += 0.125 {
+			for b := 0.0; b <= 1; b += 0.125 {
+				coords = append(coords, [3]float64{r, g, b})
+			}
+		}
+	}
+	return coords
+}
+
+func TestConvertSlice(t *testing.T) {
+	coords := convertSliceTestCoords()
+	for _, to := range []*Space{SRGB, DisplayP3, Oklch, Lab, XYZ_D50, YCbCr709} {
+		dst := make([][3]float64, len(coords))
+		SRGB.ConvertSlice(to, coords, dst)
+		for i, c := range coords {
+			if want := SRGB.Convert(to, c); dst[i] != want {
+				t.Errorf("%s: converting %v: got %v, want %v", to.ID, c, dst[i], want)
+			}
+		}
+
+		inPlace := slices.Clone(coords)
+		SRGB.ConvertSlice(to, inPlace, inPlace)
+		if !slices.Equal(inPlace, dst) {
+			t.Errorf("%s: converting in place produced different results", to.ID)
+		}
+	}
+}
+
+func BenchmarkConvertSlice(b *testing.B) {
+	coords := convertSliceTestCoords()
+	dst := make([][3]float64, len(coords))
+
+	b.Run("loop", func(b *testing.B) {
+		for range b.N {
+			for i := range coords {
+				dst[i] = SRGB.Convert(Oklch, coords[i])
+			}
+		}
+	})
+	b.Run("slice", func(b *testing.B) {
+		for range b.N {
+			SRGB.ConvertSlice(Oklch, coords, dst)
+		}
+	})
+}
+
+func TestNonFinite(t *testing.T) {
+	nan := math.NaN()
+	inf := math.Inf(1)
+
+	lin := SRGB.Convert(LinearSRGB, [3]float64{nan, 0.5, 1})
+	if !math.IsNaN(lin[0]) || math.IsNaN(lin[1]) || math.IsNaN(lin[2]) {
+		t.Errorf("sRGB -> linear sRGB: got %v, want NaN only in the first coordinate", lin)
+	}
+
+	for _, in := range [][3]float64{{nan, 0.5, 1}, {0.5, inf, 1}, {0.5, 0.5, math.Inf(-1)}} {
+		lab := SRGB.Convert(Oklab, in)
+		if isFinite(lab) {
+			t.Errorf("sRGB -> Oklab: converting %v: got finite %v", in, lab)
+		}
+
+		if SRGB.InGamut(in) {
+			t.Errorf("%v is in gamut", in)
+		}
+		// As a color, NaN marks a missing component, which is treated as zero.
+		c := Make(SRGB, in[0], in[1], in[2], 1)
+		if got, want := c.InGamut(), math.IsNaN(in[0]); got != want {
+			t.Errorf("%v: got InGamut() == %t, want %t", c, got, want)
+		}
+		// NaN marks a missing component, which Color.Convert and GamutMapCSS
+		// treat as zero. Infinities can't be mapped.
+		m := GamutMapCSS(&c, DisplayP3)
+		if math.IsNaN(in[0]) {
+			want := Make(SRGB, 0, in[1], in[2], 1)
+			want = want.Convert(DisplayP3)
+			if !m.ApproxEqual(&want, DisplayP3, 1e-9) {
+				t.Errorf("mapping %v: got %v, want %v", in, m, want)
+			}
+		} else if m.Space.InGamut(m.Values) {
+			t.Errorf("mapping %v resulted in in-gamut color %v", in, m)
+		}
+	}
+
+	for _, in := range [][3]float64{{0.5, 0.1, nan}, {0.5, 0.1, inf}, {0.5, inf, 30}} {
+		if Oklch.InGamut(in) {
+			t.Errorf("%v is in gamut", in)
+		}
+		c := Make(Oklch, in[0], in[1], in[2], 1)
+		// This used to never terminate for infinite chroma.
+		GamutMapCSS(&c, SRGB)
+	}
+}
+
+func TestLookupSpace(t *testing.T) {
+	tests := []struct {
+		id   string
+		want *Space
+	}{
+		{"srgb", SRGB},
+		{"SRGB", SRGB},
+		{"--OkLch", Oklch},
+		{"XYZ-D65", XYZ_D65},
+		{"xyz", XYZ_D65},
+		{"XYZ", XYZ_D65},
+	}
+	for _, tt := range tests {
+		if got, ok := LookupSpace(tt.id); !ok || got != tt.want {
+			t.Errorf("%q: got (%v, %t), want %s", tt.id, got, ok, tt.want.ID)
+		}
+	}
+	if _, ok := LookupSpace("nonexistent"); ok {
+		t.Errorf("found nonexistent space")
+	}
+
+	RegisterSpaceAlias("test-P3", "display-p3")
+	if got, ok := LookupSpace("TEST-p3"); !ok || got != DisplayP3 {
+		t.Errorf("alias didn't resolve to Display P3: got (%v, %t)", got, ok)
+	}
+	c, ok := Parse("color(test-p3 1 0 0)")
+	if want := Make(DisplayP3, 1, 0, 0, 1); !ok || c != want {
+		t.Errorf("got (%v, %t), want %v", c, ok, want)
+	}
+	c, ok = Parse("color(SRGB 1 0 0)")
+	if want := Make(SRGB, 1, 0, 0, 1); !ok || c != want {
+		t.Errorf("got (%v, %t), want %v", c, ok, want)
+	}
+}
+
+func TestSpaces(t *testing.T) {
+	got := slices.Collect(Spaces())
+	if !slices.IsSortedFunc(got, func(a, b *Space) int { return strings.Compare(a.ID, b.ID) }) {
+		t.Errorf("spaces aren't sorted by ID")
+	}
+	for _, cs := range []*Space{
+		XYZ_D50, XYZ_D65, XyY, LinearSRGB, SRGB, LinearDisplayP3, DisplayP3,
+		Oklab, Oklch, Lab, LCh, LinearProPhoto, ProPhoto, LinearRec2020, Rec2020,
+		YCbCr601, YCbCr709, YCbCr2020,
+	} {
+		if !slices.Contains(got, cs) {
+			t.Errorf("%s isn't included", cs.ID)
+		}
+	}
+}
+
+func TestRegisterSpaceErr(t *testing.T) {
+	if err := RegisterSpaceErr(SRGB); err != nil {
+		t.Errorf("re-registering sRGB: %s", err)
+	}
+
+	conflicting := (&Space{
+		ID:       "SRGB",
+		Name:     "My sRGB",
+		Base:     LinearSRGB,
+		ToBase:   SRGB.ToBase,
+		FromBase: SRGB.FromBase,
+	}).Init()
+	if err := RegisterSpaceErr(conflicting); err == nil {
+		t.Errorf("expected error for conflicting ID")
+	}
+	if got, _ := LookupSpace("srgb"); got != SRGB {
+		t.Errorf("conflicting space replaced sRGB")
+	}
+
+	custom := (&Space{
+		ID:       "test-register-err",
+		Name:     "Custom",
+		Base:     LinearSRGB,
+		ToBase:   SRGB.ToBase,
+		FromBase: SRGB.FromBase,
+	}).Init()
+	for range 2 {
+		if err := RegisterSpaceErr(custom); err != nil {
+			t.Errorf("registering custom space: %s", err)
+		}
+	}
+}
+
+func TestUnregisterSpace(t *testing.T) {
+	custom := (&Space{
+		ID:       "test-unregister",
+		Name:     "Custom",
+		Base:     LinearSRGB,
+		ToBase:   SRGB.ToBase,
+		FromBase: SRGB.FromBase,
+	}).Init()
+	RegisterSpace(custom)
+	if !UnregisterSpace("TEST-unregister") {
+		t.Errorf("custom space wasn't registered")
+	}
+	if _, ok := LookupSpace("test-unregister"); ok {
+		t.Errorf("custom space is still registered")
+	}
+	if UnregisterSpace("test-unregister") {
+		t.Errorf("unregistered space twice")
+	}
+
+	// Override a built-in space and restore it afterwards.
+	gamma := (&Space{
+		ID:   "srgb",
+		Name: "sRGB (gamma 2.2)",
+		Base: LinearSRGB,
+		ToBase: func(c *[3]float64) [3]float64 {
+			return [3]float64{math.Pow(c[0], 2.2), math.Pow(c[1], 2.2), math.Pow(c[2], 2.2)}
+		},
+		FromBase: func(c *[3]float64) [3]float64 {
+			return [3]float64{math.Pow(c[0], 1/2.2), math.Pow(c[1], 1/2.2), math.Pow(c[2], 1/2.2)}
+		},
+	}).Init()
+	OverrideSpace(gamma)
+	defer OverrideSpace(SRGB)
+	if got, _ := LookupSpace("srgb"); got != gamma {
+		t.Errorf("override wasn't registered")
+	}
+	if c, _ := Parse("color(srgb 0.5 0.5 0.5)"); c.Space != gamma {
+		t.Errorf("Parse didn't use override")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for cs := range Spaces() {
+		if err := cs.Validate(); err != nil {
+			t.Errorf("%s: %s", cs.ID, err)
+		}
+	}
+
+	custom := (&Space{
+		ID:       "test-validate",
+		Name:     "Custom",
+		Base:     LinearSRGB,
+		ToBase:   SRGB.ToBase,
+		FromBase: SRGB.FromBase,
+	}).Init()
+	if err := custom.Validate(); err != nil {
+		t.Errorf("well-formed space: %s", err)
+	}
+
+	orphan := (&Space{
+		ID:     "test-orphan",
+		Name:   "Orphan",
+		Coords: RGBCoordinates,
+	}).Init()
+	child := (&Space{
+		ID:       "test-orphan-child",
+		Name:     "Orphan child",
+		Base:     orphan,
+		ToBase:   SRGB.ToBase,
+		FromBase: SRGB.FromBase,
+	}).Init()
+	noFuncs := (&Space{
+		ID:   "test-no-funcs",
+		Name: "No funcs",
+		Base: LinearSRGB,
+	}).Init()
+	uninitialized := &Space{
+		ID:       "test-uninitialized",
+		Name:     "Uninitialized",
+		Base:     LinearSRGB,
+		ToBase:   SRGB.ToBase,
+		FromBase: SRGB.FromBase,
+	}
+	for _, cs := range []*Space{orphan, child, noFuncs, uninitialized} {
+		if err := cs.Validate(); err == nil {
+			t.Errorf("%s: expected error", cs.ID)
+		}
+		if err := RegisterSpaceErr(cs); err == nil {
+			t.Errorf("%s: registered malformed space", cs.ID)
+		}
+	}
+}
+
+func TestInGamutTol(t *testing.T) {
+	values := [3]float64{1.00001, 0.5, -0.00001}
+	if !SRGB.InGamut(values) {
+		t.Errorf("%v isn't in gamut with the default tolerance", values)
+	}
+	if SRGB.InGamutTol(values, 0) {
+		t.Errorf("%v is in gamut with zero tolerance", values)
+	}
+	if !SRGB.InGamutTol([3]float64{1, 0.5, 0}, 0) {
+		t.Errorf("boundary values aren't in gamut with zero tolerance")
+	}
+	if !SRGB.InGamutTol([3]float64{1.05, 0.5, 0}, 0.1) {
+		t.Errorf("lenient check failed")
+	}
+}
+
+func TestIsPolar(t *testing.T) {
+	for _, cs := range []*Space{Oklch, LCh} {
+		if !cs.IsPolar() {
+			t.Errorf("%s isn't polar", cs.ID)
+		}
+	}
+	for _, cs := range []*Space{SRGB, Lab, Oklab, XYZ_D65, XyY, YCbCr709} {
+		if cs.IsPolar() {
+			t.Errorf("%s is polar", cs.ID)
+		}
+	}
+}
+
+func TestOklabToe(t *testing.T) {
+	for _, v := range []float64{0, 1} {
+		if got := OklabToe(v); math.Abs(got-v) > 1e-15 {
+			t.Errorf("OklabToe(%g) = %g, want %g", v, got, v)
+		}
+		if got := OklabToeInv(v); math.Abs(got-v) > 1e-15 {
+			t.Errorf("OklabToeInv(%g) = %g, want %g", v, got, v)
+		}
+	}
+
+	prev := -1.0
+	for i := range 1001 {
+		l := float64(i) / 1000
+		lr := OklabToe(l)
+		if lr <= prev {
+			t.Errorf("OklabToe isn't increasing at %g", l)
+		}
+		prev = lr
+		if got := OklabToeInv(lr); math.Abs(got-l) > 1e-15 {
+			t.Errorf("OklabToeInv(OklabToe(%g)) = %g", l, got)
+		}
+		if got := OklabToe(OklabToeInv(l)); math.Abs(got-l) > 1e-15 {
+			t.Errorf("OklabToe(OklabToeInv(%g)) = %g", l, got)
+		}
+	}
+
+	// Lr approximates CIELAB's L*, which is darker for dark colors than
+	// Oklab's L.
+	for _, v := range []float64{0.05, 0.1, 0.2} {
+		c := Make(SRGB, v, v, v, 1)
+		l := c.Convert(Oklab).Values[0]
+		lstar := c.Convert(Lab).Values[0] / 100
+		if lr := OklabToe(l); math.Abs(lr-lstar) >= math.Abs(l-lstar) {
+			t.Errorf("gray %g: Lr %g isn't closer to L* %g than L %g is", v, lr, lstar, l)
+		}
+	}
+}
+
+func TestSRGBTransfer(t *testing.T) {
+	for i := -100; i <= 200; i++ {
+		v := float64(i) / 100
+		if got := SRGBDecode(SRGBEncode(v)); math.Abs(got-v) > 1e-12 {
+			t.Errorf("SRGBDecode(SRGBEncode(%g)) = %g", v, got)
+		}
+		if got := SRGBEncode(SRGBDecode(v)); math.Abs(got-v) > 1e-12 {
+			t.Errorf("SRGBEncode(SRGBDecode(%g)) = %g", v, got)
+		}
+		if got, want := SRGBEncode(-v), -SRGBEncode(v); got != want {
+			t.Errorf("SRGBEncode(%g) = %g, want %g", -v, got, want)
+		}
+	}
+
+	// The linear segment applies up to and including the breakpoints, and the
+	// two segments meet there.
+	if got, want := SRGBEncode(0.0031308), 0.0031308*12.92; got != want {
+		t.Errorf("SRGBEncode(0.0031308) = %g, want %g", got, want)
+	}
+	if got, want := SRGBDecode(0.04045), 0.04045/12.92; got != want {
+		t.Errorf("SRGBDecode(0.04045) = %g, want %g", got, want)
+	}
+	for _, x := range []float64{0.0031308, 0.04045 / 12.92} {
+		below, above := SRGBEncode(math.Nextafter(x, 0)), SRGBEncode(math.Nextafter(x, 1))
+		if math.Abs(above-below) > 1e-6 {
+			t.Errorf("SRGBEncode is discontinuous at %g: %g vs %g", x, below, above)
+		}
+	}
+
+	c := Make(LinearSRGB, 0.2, 0.5, 0.8, 1)
+	want := c.Convert(SRGB)
+	got := [3]float64{SRGBEncode(0.2), SRGBEncode(0.5), SRGBEncode(0.8)}
+	if got != want.Values {
+		t.Errorf("got %v, want %v", got, want.Values)
+	}
+}
+
+func TestSRGBGamma22(t *testing.T) {
+	if cs, ok := LookupSpace("srgb-gamma22"); !ok || cs != SRGBGamma22 {
+		t.Errorf("SRGBGamma22 isn't registered")
+	}
+
+	mid := Make(LinearSRGB, 0.2, 0.2, 0.2, 1)
+	flat := mid.Convert(SRGBGamma22)
+	piecewise := mid.Convert(SRGB)
+	if want := math.Pow(0.2, 1/2.2); math.Abs(flat.Values[0]-want) > 1e-15 {
+		t.Errorf("got %g, want %g", flat.Values[0], want)
+	}
+	// The curves are close for midtones, but not identical.
+	if d := math.Abs(flat.Values[0] - piecewise.Values[0]); d == 0 || d > 0.01 {
+		t.Errorf("got %g and %g for gamma 2.2 and sRGB", flat.Values[0], piecewise.Values[0])
+	}
+
+	for _, c := range []Color{
+		Make(LinearSRGB, 0, 0.5, 1, 1),
+		Make(LinearSRGB, 0.001, 0.02, 0.3, 1),
+		Make(LinearSRGB, -0.1, 1.2, 0.7, 1),
+	} {
+		for _, cs := range []*Space{SRGB, SRGBGamma22} {
+			enc := c.Convert(cs)
+			back := enc.Convert(LinearSRGB)
+			if !approxValues(back.Values, c.Values, 1e-12) {
+				t.Errorf("%v: got %v after round trip through %s", c, back, cs.Name)
+			}
+		}
+	}
+}
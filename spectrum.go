@@ -0,0 +1,190 @@
+package color
+
+// Observer identifies a CIE standard colorimetric observer, that is, a set of
+// color matching functions.
+type Observer int
+
+const (
+	// ObserverCIE1931TwoDeg is the CIE 1931 2° standard observer.
+	ObserverCIE1931TwoDeg Observer = iota
+	// ObserverCIE1964TenDeg is the CIE 1964 10° supplementary standard
+	// observer.
+	ObserverCIE1964TenDeg
+)
+
+// The color matching functions of the standard observers, tabulated at 10 nm
+// intervals from 380 nm to 780 nm, from CIE 15:2004.
+const (
+	cmfStart = 380.0
+	cmfEnd   = 780.0
+	cmfStep  = 10.0
+)
+
+var cmfCIE1931TwoDeg = [41][3]float64{
+	{0.001368, 0.000039, 0.006450}, // 380 nm
+	{0.004243, 0.000120, 0.020050}, // 390 nm
+	{0.014310, 0.000396, 0.067850}, // 400 nm
+	{0.043510, 0.001210, 0.207400}, // 410 nm
+	{0.134380, 0.004000, 0.645600}, // 420 nm
+	{0.283900, 0.011600, 1.385600}, // 430 nm
+	{0.348280, 0.023000, 1.747060}, // 440 nm
+	{0.336200, 0.038000, 1.772110}, // 450 nm
+	{0.290800, 0.060000, 1.669200}, // 460 nm
+	{0.195360, 0.090980, 1.287640}, // 470 nm
+	{0.095640, 0.139020, 0.812950}, // 480 nm
+	{0.032010, 0.208020, 0.465180}, // 490 nm
+	{0.004900, 0.323000, 0.272000}, // 500 nm
+	{0.009300, 0.503000, 0.158200}, // 510 nm
+	{0.063270, 0.710000, 0.078250}, // 520 nm
+	{0.165500, 0.862000, 0.042160}, // 530 nm
+	{0.290400, 0.954000, 0.020300}, // 540 nm
+	{0.433450, 0.994950, 0.008750}, // 550 nm
+	{0.594500, 0.995000, 0.003900}, // 560 nm
+	{0.762100, 0.952000, 0.002100}, // 570 nm
+	{0.916300, 0.870000, 0.001650}, // 580 nm
+	{1.026300, 0.757000, 0.001100}, // 590 nm
+	{1.062200, 0.631000, 0.000800}, // 600 nm
+	{1.002600, 0.503000, 0.000340}, // 610 nm
+	{0.854450, 0.381000, 0.000190}, // 620 nm
+	{0.642400, 0.265000, 0.000050}, // 630 nm
+	{0.447900, 0.175000, 0.000020}, // 640 nm
+	{0.283500, 0.107000, 0.000000}, // 650 nm
+	{0.164900, 0.061000, 0.000000}, // 660 nm
+	{0.087400, 0.032000, 0.000000}, // 670 nm
+	{0.046770, 0.017000, 0.000000}, // 680 nm
+	{0.022700, 0.008210, 0.000000}, // 690 nm
+	{0.011359, 0.004102, 0.000000}, // 700 nm
+	{0.005790, 0.002091, 0.000000}, // 710 nm
+	{0.002899, 0.001047, 0.000000}, // 720 nm
+	{0.001440, 0.000520, 0.000000}, // 730 nm
+	{0.000690, 0.000249, 0.000000}, // 740 nm
+	{0.000332, 0.000120, 0.000000}, // 750 nm
+	{0.000166, 0.000060, 0.000000}, // 760 nm
+	{0.000083, 0.000030, 0.000000}, // 770 nm
+	{0.000042, 0.000015, 0.000000}, // 780 nm
+}
+
+var cmfCIE1964TenDeg = [41][3]float64{
+	{0.000160, 0.000017, 0.000705}, // 380 nm
+	{0.002362, 0.000253, 0.010482}, // 390 nm
+	{0.019110, 0.002004, 0.086011}, // 400 nm
+	{0.084736, 0.008756, 0.389366}, // 410 nm
+	{0.204492, 0.021391, 0.972542}, // 420 nm
+	{0.314679, 0.038676, 1.553480}, // 430 nm
+	{0.383734, 0.062077, 1.967280}, // 440 nm
+	{0.370702, 0.089456, 1.994800}, // 450 nm
+	{0.302273, 0.128201, 1.745370}, // 460 nm
+	{0.195618, 0.185190, 1.317560}, // 470 nm
+	{0.080507, 0.253589, 0.772125}, // 480 nm
+	{0.016172, 0.339133, 0.415254}, // 490 nm
+	{0.003816, 0.460777, 0.218502}, // 500 nm
+	{0.037465, 0.606741, 0.112044}, // 510 nm
+	{0.117749, 0.761757, 0.060709}, // 520 nm
+	{0.236491, 0.875211, 0.030451}, // 530 nm
+	{0.376772, 0.961988, 0.013676}, // 540 nm
+	{0.529826, 0.991761, 0.003988}, // 550 nm
+	{0.705224, 0.997340, 0.000000}, // 560 nm
+	{0.878655, 0.955552, 0.000000}, // 570 nm
+	{1.014160, 0.868934, 0.000000}, // 580 nm
+	{1.118520, 0.777405, 0.000000}, // 590 nm
+	{1.123990, 0.658341, 0.000000}, // 600 nm
+	{1.030480, 0.527963, 0.000000}, // 610 nm
+	{0.856297, 0.398057, 0.000000}, // 620 nm
+	{0.647467, 0.283493, 0.000000}, // 630 nm
+	{0.431567, 0.179828, 0.000000}, // 640 nm
+	{0.268329, 0.107633, 0.000000}, // 650 nm
+	{0.152568, 0.060281, 0.000000}, // 660 nm
+	{0.081261, 0.031800, 0.000000}, // 670 nm
+	{0.040851, 0.015905, 0.000000}, // 680 nm
+	{0.019941, 0.007749, 0.000000}, // 690 nm
+	{0.009577, 0.003718, 0.000000}, // 700 nm
+	{0.004553, 0.001768, 0.000000}, // 710 nm
+	{0.002175, 0.000846, 0.000000}, // 720 nm
+	{0.001045, 0.000407, 0.000000}, // 730 nm
+	{0.000508, 0.000199, 0.000000}, // 740 nm
+	{0.000251, 0.000098, 0.000000}, // 750 nm
+	{0.000126, 0.000050, 0.000000}, // 760 nm
+	{0.000065, 0.000025, 0.000000}, // 770 nm
+	{0.000033, 0.000013, 0.000000}, // 780 nm
+}
+
+func (obs Observer) table() *[41][3]float64 {
+	switch obs {
+	case ObserverCIE1931TwoDeg:
+		return &cmfCIE1931TwoDeg
+	case ObserverCIE1964TenDeg:
+		return &cmfCIE1964TenDeg
+	default:
+		panic("invalid observer")
+	}
+}
+
+// ColorMatchingFunctions returns the values of the observer's color matching
+// functions x̄, ȳ, and z̄ at the given wavelength in nanometers. Values between
+// the tabulated wavelengths are linearly interpolated. Outside the range of
+// 380 nm to 780 nm, all values are zero.
+func (obs Observer) ColorMatchingFunctions(wavelength float64) [3]float64 {
+	table := obs.table()
+	if !(wavelength >= cmfStart && wavelength <= cmfEnd) {
+		return [3]float64{}
+	}
+	pos := (wavelength - cmfStart) / cmfStep
+	i := min(int(pos), len(table)-2)
+	t := pos - float64(i)
+	return [3]float64{
+		lerp(table[i][0], table[i+1][0], t),
+		lerp(table[i][1], table[i+1][1], t),
+		lerp(table[i][2], table[i+1][2], t),
+	}
+}
+
+// SpectrumToXYZ computes the XYZ tristimulus values of a spectral power
+// distribution, as seen by the observer. The distribution is described by
+// values sampled at the given wavelengths, in nanometers, which must be sorted
+// in increasing order but don't have to be evenly spaced. The product of the
+// distribution and the color matching functions is integrated using the
+// trapezoidal rule.
+//
+// The result is scaled so that a constant distribution of 1 across the visible
+// spectrum (380 nm to 780 nm) has Y = 1. For reflective or transmissive
+// samples, the distribution should be the product of the illuminant and the
+// sample's reflectance, and the result should be normalized by the Y of the
+// illuminant.
+func SpectrumToXYZ(wavelengths, values []float64, observer Observer) [3]float64 {
+	if len(wavelengths) != len(values) {
+		panic("wavelengths and values have different lengths")
+	}
+	if len(wavelengths) < 2 {
+		panic("need at least two samples")
+	}
+
+	integrand := func(i int) [3]float64 {
+		cmf := observer.ColorMatchingFunctions(wavelengths[i])
+		return [3]float64{cmf[0] * values[i], cmf[1] * values[i], cmf[2] * values[i]}
+	}
+
+	var xyz [3]float64
+	prev := integrand(0)
+	for i := 1; i < len(wavelengths); i++ {
+		dλ := wavelengths[i] - wavelengths[i-1]
+		if !(dλ > 0) {
+			panic("wavelengths must be strictly increasing")
+		}
+		cur := integrand(i)
+		for j := range xyz {
+			xyz[j] += (prev[j] + cur[j]) / 2 * dλ
+		}
+		prev = cur
+	}
+
+	// Normalize by the integral of ȳ over the visible spectrum.
+	table := observer.table()
+	var ySum float64
+	for i := 1; i < len(table); i++ {
+		ySum += (table[i-1][1] + table[i][1]) / 2 * cmfStep
+	}
+	for j := range xyz {
+		xyz[j] /= ySum
+	}
+	return xyz
+}
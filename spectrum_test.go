@@ -0,0 +1,65 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+// The relative spectral power distribution of CIE standard illuminant D65,
+// from 380 nm to 780 nm in 10 nm steps.
+var spdD65 = []float64{
+	49.9755, 54.6482, 82.7549, 91.486, 93.4318, 86.6823, 104.865, 117.008,
+	117.812, 114.861, 115.923, 108.811, 109.354, 107.802, 104.790, 107.689,
+	104.405, 104.046, 100.000, 96.3342, 95.788, 88.6856, 90.0062, 89.5991,
+	87.6987, 83.2886, 83.6992, 80.0268, 80.2146, 82.2778, 78.2842, 69.7213,
+	71.6091, 74.349, 61.604, 69.8856, 75.087, 63.5927, 46.4182, 66.8054,
+	63.3828,
+}
+
+func xyzToXY(xyz [3]float64) (x, y float64) {
+	sum := xyz[0] + xyz[1] + xyz[2]
+	return xyz[0] / sum, xyz[1] / sum
+}
+
+func TestSpectrumToXYZ(t *testing.T) {
+	wavelengths := make([]float64, len(spdD65))
+	for i := range wavelengths {
+		wavelengths[i] = 380 + 10*float64(i)
+	}
+
+	tests := []struct {
+		observer Observer
+		want     *Chromaticity
+	}{
+		{ObserverCIE1931TwoDeg, WhitesCIE2004TwoDegD65},
+		{ObserverCIE1964TenDeg, WhitesCIE2004TenDegD65},
+	}
+	for _, tt := range tests {
+		x, y := xyzToXY(SpectrumToXYZ(wavelengths, spdD65, tt.observer))
+		// The 10 nm sampling introduces small errors.
+		if math.Abs(x-tt.want.X) > 2e-4 || math.Abs(y-tt.want.Y) > 2e-4 {
+			t.Errorf("observer %d: got (%g, %g), want %v", tt.observer, x, y, *tt.want)
+		}
+	}
+
+	// An equal-energy spectrum has Y = 1 and lies at the center of the
+	// chromaticity diagram.
+	flat := make([]float64, len(wavelengths))
+	for i := range flat {
+		flat[i] = 1
+	}
+	xyz := SpectrumToXYZ(wavelengths, flat, ObserverCIE1931TwoDeg)
+	if math.Abs(xyz[1]-1) > 1e-12 {
+		t.Errorf("got Y = %g, want 1", xyz[1])
+	}
+	if x, y := xyzToXY(xyz); math.Abs(x-1.0/3) > 1e-3 || math.Abs(y-1.0/3) > 1e-3 {
+		t.Errorf("got (%g, %g), want (1/3, 1/3)", x, y)
+	}
+
+	// A monochromatic line at 520 nm, with non-uniform sampling around it,
+	// lands on the spectral locus.
+	x, y := xyzToXY(SpectrumToXYZ([]float64{500, 519, 520, 521, 600}, []float64{0, 0, 1, 0, 0}, ObserverCIE1931TwoDeg))
+	if math.Abs(x-0.07430) > 2e-3 || math.Abs(y-0.83380) > 2e-3 {
+		t.Errorf("got (%g, %g), want (0.07430, 0.83380)", x, y)
+	}
+}
@@ -0,0 +1,62 @@
+package color
+
+import "math"
+
+// A ToneMapOp is a tone mapping operator. It maps non-negative relative
+// luminances, which may exceed 1 in high dynamic range content, to the range
+// [0, 1].
+type ToneMapOp func(y float64) float64
+
+// Reinhard is the simple Reinhard operator, y / (1 + y). It maps 0 to 0 and
+// approaches, but never reaches, 1 as y grows.
+func Reinhard(y float64) float64 {
+	return y / (1 + y)
+}
+
+// ReinhardExtended returns the extended Reinhard operator, which maps the
+// luminance white, and all higher luminances, to 1. As white approaches
+// infinity, the operator approaches [Reinhard].
+func ReinhardExtended(white float64) ToneMapOp {
+	if !(white > 0) {
+		panic("white must be positive")
+	}
+	w2 := white * white
+	return func(y float64) float64 {
+		return min(y*(1+y/w2)/(1+y), 1)
+	}
+}
+
+// ACESFilmic returns Narkowicz's fit of the ACES filmic tone curve, applied
+// after scaling luminances by exposure. An exposure of 0.6 matches the
+// reference curve; higher values brighten the result.
+func ACESFilmic(exposure float64) ToneMapOp {
+	const (
+		a = 2.51
+		b = 0.03
+		c = 2.43
+		d = 0.59
+		e = 0.14
+	)
+	return func(y float64) float64 {
+		y *= exposure
+		return min(max(y*(a*y+b)/(y*(c*y+d)+e), 0), 1)
+	}
+}
+
+// ToneMap compresses the luminance of the possibly high dynamic range color c
+// using the tone mapping operator op. The luminance Y is computed in [XYZ_D65]
+// and all three XYZ coordinates are scaled by op(Y) / Y, which preserves the
+// chromaticity of c. The result is returned in c's color space. Colors with
+// non-positive luminance are returned unchanged.
+func ToneMap(c *Color, op ToneMapOp) Color {
+	xyz := c.Convert(XYZ_D65)
+	y := xyz.Values[1]
+	if !(y > 0) || math.IsInf(y, 1) {
+		return *c
+	}
+	k := op(y) / y
+	for i := range xyz.Values {
+		xyz.Values[i] *= k
+	}
+	return xyz.Convert(c.Space)
+}
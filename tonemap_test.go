@@ -0,0 +1,73 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToneMapOps(t *testing.T) {
+	ops := map[string]ToneMapOp{
+		"Reinhard":              Reinhard,
+		"ReinhardExtended(4)":   ReinhardExtended(4),
+		"ReinhardExtended(1e9)": ReinhardExtended(1e9),
+		"ACESFilmic(0.6)":       ACESFilmic(0.6),
+	}
+	for name, op := range ops {
+		if got := op(0); got != 0 {
+			t.Errorf("%s: got %g for 0, want 0", name, got)
+		}
+		prev := 0.0
+		for y := 0.01; y < 1000; y *= 1.1 {
+			got := op(y)
+			if got < prev {
+				t.Errorf("%s: not monotonic at %g: %g < %g", name, y, got, prev)
+			}
+			if got > 1 {
+				t.Errorf("%s: got %g for %g, want at most 1", name, got, y)
+			}
+			prev = got
+		}
+	}
+
+	// Reinhard approaches 1 but stays below it.
+	for _, y := range []float64{1, 10, 1e3, 1e6} {
+		if got := Reinhard(y); !(got < 1) {
+			t.Errorf("Reinhard(%g) = %g, want less than 1", y, got)
+		}
+	}
+	if got := Reinhard(1e6); got < 0.999 {
+		t.Errorf("Reinhard(1e6) = %g, want close to 1", got)
+	}
+
+	// Extended Reinhard maps white to 1 and approaches Reinhard for large
+	// white points.
+	if got := ReinhardExtended(4)(4); got != 1 {
+		t.Errorf("got %g for the white point, want 1", got)
+	}
+	if got, want := ReinhardExtended(1e9)(3), Reinhard(3); math.Abs(got-want) > 1e-9 {
+		t.Errorf("got %g, want %g", got, want)
+	}
+}
+
+func TestToneMap(t *testing.T) {
+	hdr := Make(LinearSRGB, 4, 2, 1, 1)
+	got := ToneMap(&hdr, Reinhard)
+	if got.Space != LinearSRGB {
+		t.Errorf("got space %s, want %s", got.Space.Name, LinearSRGB.Name)
+	}
+
+	xyzIn := hdr.Convert(XYZ_D65)
+	xyzOut := got.Convert(XYZ_D65)
+	if y, want := xyzOut.Values[1], Reinhard(xyzIn.Values[1]); math.Abs(y-want) > 1e-12 {
+		t.Errorf("got luminance %g, want %g", y, want)
+	}
+	// The chromaticity is preserved, so the RGB channels keep their ratios.
+	if r1, r2 := got.Values[0]/got.Values[1], got.Values[1]/got.Values[2]; math.Abs(r1-2) > 1e-9 || math.Abs(r2-2) > 1e-9 {
+		t.Errorf("got %v, want channels with ratios 4:2:1", got)
+	}
+
+	black := Make(SRGB, 0, 0, 0, 1)
+	if got := ToneMap(&black, ACESFilmic(0.6)); got != black {
+		t.Errorf("got %v, want %v", got, black)
+	}
+}
@@ -0,0 +1,83 @@
+package color
+
+import "fmt"
+
+func init() {
+	RegisterSpace(YCbCr601)
+	RegisterSpace(YCbCr709)
+	RegisterSpace(YCbCr2020)
+}
+
+// NewYCbCrSpace returns a new Y'CbCr color space with the specified name and
+// ID, based on the gamma-encoded RGB color space base. The luma coefficients of
+// red and blue are kr and kb; the coefficient of green is 1 - kr - kb.
+//
+// If studio is false, the space uses the full range, with Y' in [0, 1] and Cb
+// and Cr in [-0.5, 0.5]. If studio is true, it uses the studio (also known as
+// limited or TV) range, scaled like 8-bit code values divided by 255: Y' is in
+// [16/255, 235/255] and Cb and Cr are in [16/255, 240/255], centered on
+// 128/255.
+func NewYCbCrSpace(name, id string, base *Space, kr, kb float64, studio bool) *Space {
+	kg := 1 - kr - kb
+
+	// Scale and offset of the luma and chroma coordinates.
+	yScale, yOff := 1.0, 0.0
+	cScale, cOff := 1.0, 0.0
+	if studio {
+		yScale, yOff = 219.0/255, 16.0/255
+		cScale, cOff = 224.0/255, 128.0/255
+	}
+
+	return (&Space{
+		ID:   id,
+		Name: name,
+		Base: base,
+		Coords: [3]Coordinate{
+			{Name: "Y", Range: [2]float64{yOff, yOff + yScale}},
+			{Name: "Cb", Range: [2]float64{cOff - cScale/2, cOff + cScale/2}},
+			{Name: "Cr", Range: [2]float64{cOff - cScale/2, cOff + cScale/2}},
+		},
+		FromBase: func(c *[3]float64) [3]float64 {
+			r, g, b := c[0], c[1], c[2]
+			y := kr*r + kg*g + kb*b
+			cb := (b - y) / (2 * (1 - kb))
+			cr := (r - y) / (2 * (1 - kr))
+			return [3]float64{
+				yOff + yScale*y,
+				cOff + cScale*cb,
+				cOff + cScale*cr,
+			}
+		},
+		ToBase: func(c *[3]float64) [3]float64 {
+			y := (c[0] - yOff) / yScale
+			cb := (c[1] - cOff) / cScale
+			cr := (c[2] - cOff) / cScale
+			r := y + 2*(1-kr)*cr
+			b := y + 2*(1-kb)*cb
+			g := (y - kr*r - kb*b) / kg
+			return [3]float64{r, g, b}
+		},
+	}).Init()
+}
+
+func newYCbCrSpace(standard string, base *Space, kr, kb float64) *Space {
+	return NewYCbCrSpace(
+		fmt.Sprintf("Y'CbCr (Rec. %s)", standard),
+		"ycbcr-"+standard,
+		base,
+		kr, kb,
+		false,
+	)
+}
+
+// YCbCr601 is full-range Y'CbCr with the luma coefficients of ITU-R BT.601,
+// as used by JPEG. It is based on [SRGB].
+var YCbCr601 = newYCbCrSpace("601", SRGB, 0.299, 0.114)
+
+// YCbCr709 is full-range Y'CbCr with the luma coefficients of ITU-R BT.709.
+// It is based on [SRGB], which shares its primaries with BT.709.
+var YCbCr709 = newYCbCrSpace("709", SRGB, 0.2126, 0.0722)
+
+// YCbCr2020 is full-range Y'CbCr with the non-constant luminance coefficients
+// of ITU-R BT.2020. It is based on [Rec2020].
+var YCbCr2020 = newYCbCrSpace("2020", Rec2020, 0.2627, 0.0593)
@@ -0,0 +1,70 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestYCbCr(t *testing.T) {
+	studio := NewYCbCrSpace("Y'CbCr (Rec. 709, studio)", "test-ycbcr-709-studio", SRGB, 0.2126, 0.0722, true)
+
+	for _, space := range []*Space{YCbCr601, YCbCr709, YCbCr2020, studio} {
+		yRange := space.Coords[0].Range
+		chromaZero := (space.Coords[1].Range[0] + space.Coords[1].Range[1]) / 2
+
+		for _, v := range []float64{0, 0.25, 0.5, 1} {
+			gray := Make(space.Base, v, v, v, 1)
+			got := gray.Convert(space)
+			wantY := lerp(yRange[0], yRange[1], v)
+			if math.Abs(got.Values[0]-wantY) > 1e-12 ||
+				math.Abs(got.Values[1]-chromaZero) > 1e-12 ||
+				math.Abs(got.Values[2]-chromaZero) > 1e-12 {
+				t.Errorf("%s: got %v for gray %g, want (%g, %g, %g)", space.Name, got, v, wantY, chromaZero, chromaZero)
+			}
+		}
+
+		for _, prim := range [][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0.2, 0.4, 0.6}} {
+			c := Make(space.Base, prim[0], prim[1], prim[2], 1)
+			yc := c.Convert(space)
+			if !yc.InGamut() {
+				t.Errorf("%s: %v isn't in gamut", space.Name, yc)
+			}
+			back := yc.Convert(space.Base)
+			for i := range back.Values {
+				if math.Abs(back.Values[i]-prim[i]) > 1e-12 {
+					t.Errorf("%s: %v didn't round-trip, got %v", space.Name, c, back)
+					break
+				}
+			}
+		}
+	}
+
+	// Known values for BT.601 full range, as used by JPEG.
+	red := Make(SRGB, 1, 0, 0, 1)
+	got := red.Convert(YCbCr601)
+	want := [3]float64{0.299, -0.168736, 0.5}
+	for i := range want {
+		if math.Abs(got.Values[i]-want[i]) > 1e-6 {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestRec2020(t *testing.T) {
+	for _, v := range []float64{0, 0.01, 0.018053968510807, 0.02, 0.5, 1} {
+		c := Make(LinearRec2020, v, v, v, 1)
+		enc := c.Convert(Rec2020)
+		back := enc.Convert(LinearRec2020)
+		if math.Abs(back.Values[0]-v) > 1e-12 {
+			t.Errorf("%g: got %g after round trip", v, back.Values[0])
+		}
+	}
+
+	// All of sRGB is inside Rec. 2020.
+	for c := range SampleGamut(SRGB, 0.25) {
+		if !c.InGamutOf(Rec2020) {
+			t.Errorf("%v isn't in gamut of Rec. 2020", c)
+		}
+	}
+}